@@ -0,0 +1,221 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultCertReloadInterval interval of checking PEM files of CertEntry
+	defaultCertReloadInterval = 10 * time.Second
+)
+
+// certReloader serves server certificate with tls.Config.GetCertificate.
+//
+// Certificate is swapped atomically, so handshakes in flight always see either old or new certificate.
+type certReloader struct {
+	certPemPath string
+	keyPemPath  string
+	cert        atomic.Value
+	modTime     time.Time
+	lock        sync.Mutex
+	quitChan    chan struct{}
+	quitOnce    sync.Once
+}
+
+// newCertReloader creates certReloader with certificate loaded by CertEntry.
+func newCertReloader(certEntry *rkentry.CertEntry) *certReloader {
+	reloader := &certReloader{
+		quitChan: make(chan struct{}),
+	}
+	reloader.certPemPath, reloader.keyPemPath = certEntryPaths(certEntry)
+	reloader.modTime = reloader.latestModTime()
+
+	if certEntry.Certificate != nil {
+		reloader.cert.Store(certEntry.Certificate)
+	}
+
+	return reloader
+}
+
+// GetCertificate returns current certificate, it is used as tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := r.current(); cert != nil {
+		return cert, nil
+	}
+
+	return nil, errors.New("no server certificate available")
+}
+
+// current returns current certificate.
+func (r *certReloader) current() *tls.Certificate {
+	if cert, ok := r.cert.Load().(*tls.Certificate); ok {
+		return cert
+	}
+
+	return nil
+}
+
+// reload reads PEM files again and swaps certificate.
+// If PEM files are not available, like embed.FS was used, fallback certificate would be used.
+func (r *certReloader) reload(fallback *tls.Certificate) (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cert := fallback
+	if r.hasFiles() {
+		certPEM, err := os.ReadFile(r.certPemPath)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := os.ReadFile(r.keyPemPath)
+		if err != nil {
+			return nil, err
+		}
+
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		cert = &pair
+		r.modTime = r.latestModTime()
+	}
+
+	if cert == nil {
+		return nil, errors.New("no server certificate available")
+	}
+
+	r.cert.Store(cert)
+	return cert, nil
+}
+
+// changed checks whether PEM files were modified since last reload.
+func (r *certReloader) changed() bool {
+	if !r.hasFiles() {
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.latestModTime().After(r.modTime)
+}
+
+// watch checks PEM files with interval and calls onChange if files were modified.
+func (r *certReloader) watch(interval time.Duration, onChange func()) {
+	if !r.hasFiles() || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if r.changed() {
+					onChange()
+				}
+			case <-r.quitChan:
+				return
+			}
+		}
+	}()
+}
+
+// stop watching PEM files.
+func (r *certReloader) stop() {
+	r.quitOnce.Do(func() {
+		close(r.quitChan)
+	})
+}
+
+func (r *certReloader) hasFiles() bool {
+	return len(r.certPemPath) > 0 && len(r.keyPemPath) > 0
+}
+
+// latestModTime returns latest modification time of PEM files.
+//
+// os.Stat follows symlinks, so atomic symlink swaps made by cert-manager or vault agent are detected as well.
+func (r *certReloader) latestModTime() time.Time {
+	res := time.Time{}
+
+	for _, p := range []string{r.certPemPath, r.keyPemPath} {
+		if len(p) < 1 {
+			continue
+		}
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(res) {
+			res = info.ModTime()
+		}
+	}
+
+	return res
+}
+
+// certEntryPaths returns absolute PEM file paths of CertEntry.
+//
+// rkentry.CertEntry does not expose paths, so we read them from its JSON form.
+// Paths are ignored if certificate was loaded from embed.FS.
+func certEntryPaths(certEntry *rkentry.CertEntry) (certPemPath, keyPemPath string) {
+	if certEntry == nil || rkentry.GlobalAppCtx.GetEmbedFS(rkentry.CertEntryType, certEntry.GetName()) != nil {
+		return "", ""
+	}
+
+	bytes, err := certEntry.MarshalJSON()
+	if err != nil {
+		return "", ""
+	}
+
+	m := struct {
+		CertPemPath string `json:"certPemPath"`
+		KeyPemPath  string `json:"keyPemPath"`
+	}{}
+	if err := json.Unmarshal(bytes, &m); err != nil {
+		return "", ""
+	}
+
+	return toAbsPath(m.CertPemPath), toAbsPath(m.KeyPemPath)
+}
+
+// certNotAfter returns expiration time of leaf certificate.
+func certNotAfter(cert *tls.Certificate) time.Time {
+	if cert == nil {
+		return time.Time{}
+	}
+
+	if cert.Leaf != nil {
+		return cert.Leaf.NotAfter
+	}
+
+	if len(cert.Certificate) > 0 {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			return leaf.NotAfter
+		}
+	}
+
+	return time.Time{}
+}
+
+// toAbsPath resolves path with working directory, the same way as rkentry does.
+func toAbsPath(p string) string {
+	if len(p) < 1 || filepath.IsAbs(p) {
+		return p
+	}
+
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, p)
+}
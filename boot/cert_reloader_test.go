@@ -0,0 +1,191 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCertEntryPaths(t *testing.T) {
+	// with nil
+	certPath, keyPath := certEntryPaths(nil)
+	assert.Empty(t, certPath)
+	assert.Empty(t, keyPath)
+
+	// happy case
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-cert-paths",
+				CertPemPath: "certs/server.pem",
+				KeyPemPath:  "/certs/server-key.pem",
+			},
+		},
+	})[0]
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	wd, _ := os.Getwd()
+	certPath, keyPath = certEntryPaths(certEntry)
+	assert.Equal(t, filepath.Join(wd, "certs/server.pem"), certPath)
+	assert.Equal(t, "/certs/server-key.pem", keyPath)
+}
+
+func TestCertNotAfter(t *testing.T) {
+	assert.True(t, certNotAfter(nil).IsZero())
+	assert.True(t, certNotAfter(&tls.Certificate{}).IsZero())
+
+	ca, caKey := generateCA()
+	cert := generateLeaf(ca, caKey, "localhost", nil)
+	assert.False(t, certNotAfter(&cert).IsZero())
+}
+
+func TestGinEntry_ReloadCertificate(t *testing.T) {
+	// TLS not enabled
+	assert.NotNil(t, RegisterGinEntry(WithName("ut-reload-no-tls")).ReloadCertificate())
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.pem")
+	keyPath := filepath.Join(dir, "server-key.pem")
+
+	ca, caKey := generateCA()
+	first := generateLeaf(ca, caKey, "localhost", nil)
+	writeCertFiles(t, first, certPath, keyPath, time.Now())
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-cert-reload",
+				CertPemPath: certPath,
+				KeyPemPath:  keyPath,
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	promEntry := rkentry.RegisterPromEntry(&rkentry.BootProm{
+		Enabled: true,
+	}, rkentry.WithRegistryPromEntry(prometheus.NewRegistry()))
+
+	entry := RegisterGinEntry(
+		WithName("ut-reload"),
+		WithPort(8084),
+		WithCertEntry(certEntry),
+		WithPromEntry(promEntry),
+		WithCertReloadInterval(100*time.Millisecond))
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+	time.Sleep(time.Second)
+
+	assert.Equal(t, first.Certificate[0], servedCertificate(t, 8084))
+	assert.Equal(t, float64(certNotAfter(&first).Unix()),
+		testutil.ToFloat64(entry.certNotAfterGauge.WithLabelValues("ut-reload", certListenerServer)))
+
+	// rotate certificate on disk, reloader should pick it up
+	second := generateLeaf(ca, caKey, "localhost", nil)
+	writeCertFiles(t, second, certPath, keyPath, time.Now().Add(time.Minute))
+	time.Sleep(time.Second)
+
+	assert.Equal(t, second.Certificate[0], servedCertificate(t, 8084))
+	assert.Equal(t, second.Certificate[0], entry.getCertReloader().current().Certificate[0])
+	// CertEntry is not modified
+	assert.Equal(t, first.Certificate[0], entry.CertEntry.Certificate.Certificate[0])
+
+	// broken files should keep current certificate
+	assert.Nil(t, os.WriteFile(certPath, []byte("invalid"), 0644))
+	assert.NotNil(t, entry.ReloadCertificate())
+	assert.Equal(t, second.Certificate[0], servedCertificate(t, 8084))
+}
+
+func TestGinEntry_ReloadCertificate_Management(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.pem")
+	keyPath := filepath.Join(dir, "server-key.pem")
+
+	ca, caKey := generateCA()
+	first := generateLeaf(ca, caKey, "localhost", nil)
+	writeCertFiles(t, first, certPath, keyPath, time.Now())
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-cert-reload-mgmt",
+				CertPemPath: certPath,
+				KeyPemPath:  keyPath,
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	promEntry := rkentry.RegisterPromEntry(&rkentry.BootProm{
+		Enabled: true,
+	}, rkentry.WithRegistryPromEntry(prometheus.NewRegistry()))
+
+	// gin server without TLS, management server with TLS
+	entry := RegisterGinEntry(
+		WithName("ut-reload-mgmt"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithManagementPort(8085),
+		WithManagementCertEntry(certEntry),
+		WithPromEntry(promEntry),
+		WithCertReloadInterval(100*time.Millisecond))
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assert.Equal(t, first.Certificate[0], servedCertificate(t, 8085))
+	assert.Equal(t, float64(certNotAfter(&first).Unix()),
+		testutil.ToFloat64(entry.certNotAfterGauge.WithLabelValues("ut-reload-mgmt", certListenerManagement)))
+
+	// rotate certificate on disk, reloader of management server should pick it up
+	second := generateLeaf(ca, caKey, "localhost", nil)
+	writeCertFiles(t, second, certPath, keyPath, time.Now().Add(time.Minute))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(second.Certificate[0], servedCertificate(t, 8085))
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Nil(t, entry.ReloadCertificate())
+}
+
+func writeCertFiles(t *testing.T, cert tls.Certificate, certPath, keyPath string, modTime time.Time) {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(cert.PrivateKey.(*rsa.PrivateKey)),
+	})
+
+	assert.Nil(t, os.WriteFile(certPath, certPEM, 0644))
+	assert.Nil(t, os.WriteFile(keyPath, keyPEM, 0600))
+	assert.Nil(t, os.Chtimes(certPath, modTime, modTime))
+	assert.Nil(t, os.Chtimes(keyPath, modTime, modTime))
+}
+
+func servedCertificate(t *testing.T, port int) []byte {
+	conn, err := tls.Dial("tcp", "localhost:"+strconv.Itoa(port), &tls.Config{InsecureSkipVerify: true})
+	assert.Nil(t, err)
+	if conn == nil {
+		return nil
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0].Raw
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const (
+	// TrustedPlatformCloudflare trust CF-Connecting-IP header set by Cloudflare
+	TrustedPlatformCloudflare = "cloudflare"
+	// TrustedPlatformGoogleAppEngine trust X-Appengine-Remote-Addr header set by Google App Engine
+	TrustedPlatformGoogleAppEngine = "googleAppEngine"
+)
+
+// ParseTrustedPlatform convert platform name into header which gin.Engine trusts.
+//
+// Supported names: cloudflare, googleAppEngine, any other value would be treated as header name.
+func ParseTrustedPlatform(platform string) string {
+	switch strings.ToLower(platform) {
+	case TrustedPlatformCloudflare:
+		return gin.PlatformCloudflare
+	case strings.ToLower(TrustedPlatformGoogleAppEngine):
+		return gin.PlatformGoogleAppEngine
+	}
+
+	return platform
+}
+
+// configureClientIP applies trusted proxies, trusted platform and remote IP headers to Router.
+func (entry *GinEntry) configureClientIP() error {
+	// nil means default of gin which trusts all proxies
+	if entry.trustedProxies != nil {
+		if err := entry.Router.SetTrustedProxies(entry.trustedProxies); err != nil {
+			return err
+		}
+	}
+
+	if len(entry.trustedPlatform) > 0 {
+		entry.Router.TrustedPlatform = entry.trustedPlatform
+	}
+
+	if len(entry.remoteIPHeaders) > 0 {
+		entry.Router.RemoteIPHeaders = entry.remoteIPHeaders
+	}
+
+	return nil
+}
+
+// WithTrustedProxies provide IPs or CIDRs of proxies whose forwarded headers are trusted while resolving client IP.
+//
+// No proxy would be trusted if called without arguments.
+func WithTrustedProxies(proxies ...string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.trustedProxies = append([]string{}, proxies...)
+	}
+}
+
+// WithTrustedPlatform provide platform name or header which contains client IP set by platform.
+func WithTrustedPlatform(platform string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.trustedPlatform = ParseTrustedPlatform(platform)
+	}
+}
+
+// WithRemoteIPHeaders provide headers which contain client IP set by trusted proxies, like X-Forwarded-For.
+func WithRemoteIPHeaders(headers ...string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.remoteIPHeaders = append(entry.remoteIPHeaders, headers...)
+	}
+}
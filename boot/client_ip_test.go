@@ -0,0 +1,109 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedPlatform(t *testing.T) {
+	assert.Empty(t, ParseTrustedPlatform(""))
+	assert.Equal(t, gin.PlatformCloudflare, ParseTrustedPlatform("cloudflare"))
+	assert.Equal(t, gin.PlatformGoogleAppEngine, ParseTrustedPlatform("googleAppEngine"))
+	assert.Equal(t, "X-Real-Client", ParseTrustedPlatform("X-Real-Client"))
+}
+
+func TestRegisterGinEntry_WithClientIP(t *testing.T) {
+	// default, all proxies are trusted
+	entry := RegisterGinEntry(WithName("ut-client-ip-default"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "1.2.3.4", clientIP(entry.Router, "10.0.0.1:1234", "X-Forwarded-For", "1.2.3.4"))
+
+	// trust none
+	entry = RegisterGinEntry(WithName("ut-client-ip-none"), WithTrustedProxies())
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "10.0.0.1", clientIP(entry.Router, "10.0.0.1:1234", "X-Forwarded-For", "1.2.3.4"))
+
+	// with invalid proxies
+	defer assertPanic(t)
+	RegisterGinEntry(WithName("ut-client-ip-invalid"), WithTrustedProxies("invalid"))
+}
+
+func TestRegisterGinEntryYAML_WithClientIP(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-client-ip-yaml
+    port: 1949
+    enabled: true
+    trustedProxies: ["10.0.0.0/8"]
+    remoteIPHeaders: ["X-Real-IP"]
+`
+	entries := RegisterGinEntryYAML([]byte(bootStr))
+	entry := entries["ut-client-ip-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	// header from trusted proxy
+	assert.Equal(t, "1.2.3.4", clientIP(entry.Router, "10.0.0.1:1234", "X-Real-IP", "1.2.3.4"))
+	// header from untrusted proxy
+	assert.Equal(t, "192.168.0.1", clientIP(entry.Router, "192.168.0.1:1234", "X-Real-IP", "1.2.3.4"))
+	// X-Forwarded-For is not in remoteIPHeaders
+	assert.Equal(t, "10.0.0.1", clientIP(entry.Router, "10.0.0.1:1234", "X-Forwarded-For", "1.2.3.4"))
+
+	// with trusted platform
+	bootStr = `
+gin:
+  - name: ut-client-ip-platform
+    port: 1949
+    enabled: true
+    trustedPlatform: cloudflare
+`
+	entries = RegisterGinEntryYAML([]byte(bootStr))
+	entry = entries["ut-client-ip-platform"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "1.2.3.4", clientIP(entry.Router, "10.0.0.1:1234", "CF-Connecting-IP", "1.2.3.4"))
+
+	// trust none with empty list
+	bootStr = `
+gin:
+  - name: ut-client-ip-none-yaml
+    port: 1949
+    enabled: true
+    trustedProxies: []
+`
+	entries = RegisterGinEntryYAML([]byte(bootStr))
+	entry = entries["ut-client-ip-none-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "10.0.0.1", clientIP(entry.Router, "10.0.0.1:1234", "X-Forwarded-For", "1.2.3.4"))
+
+	// missing list keeps default of gin, all proxies are trusted
+	bootStr = `
+gin:
+  - name: ut-client-ip-missing-yaml
+    port: 1949
+    enabled: true
+`
+	entries = RegisterGinEntryYAML([]byte(bootStr))
+	entry = entries["ut-client-ip-missing-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "1.2.3.4", clientIP(entry.Router, "10.0.0.1:1234", "X-Forwarded-For", "1.2.3.4"))
+}
+
+func clientIP(router *gin.Engine, remoteAddr, header, value string) string {
+	ctx := gin.CreateTestContextOnly(httptest.NewRecorder(), router)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-client-ip", nil)
+	ctx.Request.RemoteAddr = remoteAddr
+	ctx.Request.Header.Set(header, value)
+
+	return ctx.ClientIP()
+}
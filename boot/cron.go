@@ -0,0 +1,228 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule returns the next activation time later than t, zero time if there is none.
+type cronSchedule interface {
+	next(t time.Time) time.Time
+}
+
+// everySchedule activates with fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+// next returns t plus interval.
+func (s *everySchedule) next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// specSchedule activates on minutes matching five fields of cron spec, each field is a bit set of allowed values.
+type specSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// day of month and day of week are matched with OR if both of them are restricted, like standard cron
+	domStar, dowStar bool
+}
+
+// next returns the first minute later than t which matches all fields, searched within five years.
+func (s *specSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchDay checks day of month and day of week.
+func (s *specSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) > 0
+	dow := s.dow&(1<<uint(t.Weekday())) > 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// cronField is range and names of a field in cron spec.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronFields = []cronField{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12, names: map[string]int{
+			"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+			"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+		}},
+		// 7 is Sunday as well
+		{name: "day of week", min: 0, max: 7, names: map[string]int{
+			"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+		}},
+	}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// parseCronSpec parses standard cron spec with five fields, minute, hour, day of month, month and day of week.
+//
+// Descriptors like @daily and @hourly, and @every with duration like @every 30s are supported either.
+func parseCronSpec(spec string) (cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) < 1 {
+		return nil, errors.New("empty cron spec")
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q, %v", spec, err)
+		}
+
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid cron spec %q, interval must be positive", spec)
+		}
+
+		return &everySchedule{interval: interval}, nil
+	}
+
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron spec %q, expect 5 fields like \"*/5 * * * *\" or descriptor like @daily", spec)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i := range fields {
+		v, err := cronFields[i].parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q, %v", spec, err)
+		}
+		bits[i] = v
+	}
+
+	// Sunday could be 0 or 7
+	if bits[4]&(1<<7) > 0 {
+		bits[4] |= 1
+	}
+
+	return &specSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parse field with comma separated lists of *, value, range and step, like 1,5-10/2,*/15
+func (f *cronField) parse(field string) (uint64, error) {
+	var res uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			v, err := strconv.Atoi(part[i+1:])
+			if err != nil || v < 1 {
+				return 0, fmt.Errorf("invalid step %q of %s", part[i+1:], f.name)
+			}
+			rangePart, step = part[:i], v
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q of %s", rangePart, f.name)
+			}
+		default:
+			v, err := f.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			// value with step, like 5/10, means from value to max
+			start, end = v, v
+			if strings.Contains(part, "/") {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			res |= 1 << uint(v)
+		}
+	}
+
+	return res, nil
+}
+
+// value parses number or name of field and checks its range.
+func (f *cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of %s", s, f.name)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d of %s out of range [%d, %d]", v, f.name, f.min, f.max)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCronSpec(t *testing.T) {
+	from := time.Date(2021, time.March, 15, 10, 7, 30, 0, time.UTC) // Monday
+
+	cases := map[string]time.Time{
+		"* * * * *":         time.Date(2021, time.March, 15, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":      time.Date(2021, time.March, 15, 10, 15, 0, 0, time.UTC),
+		"5/20 * * * *":      time.Date(2021, time.March, 15, 10, 25, 0, 0, time.UTC),
+		"0 9-17/4 * * *":    time.Date(2021, time.March, 15, 13, 0, 0, 0, time.UTC),
+		"30 2 1,15 * *":     time.Date(2021, time.April, 1, 2, 30, 0, 0, time.UTC),
+		"0 0 * feb-mar sun": time.Date(2021, time.March, 21, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":         time.Date(2021, time.March, 21, 0, 0, 0, 0, time.UTC),
+		// day of month or day of week
+		"0 0 20 * mon": time.Date(2021, time.March, 20, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":   time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"@hourly":      time.Date(2021, time.March, 15, 11, 0, 0, 0, time.UTC),
+		"@daily":       time.Date(2021, time.March, 16, 0, 0, 0, 0, time.UTC),
+		"@yearly":      time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"@every 90s":   from.Add(90 * time.Second),
+	}
+
+	for spec, expected := range cases {
+		schedule, err := parseCronSpec(spec)
+		if assert.Nil(t, err, spec) {
+			assert.Equal(t, expected, schedule.next(from), spec)
+		}
+	}
+
+	// never
+	schedule, err := parseCronSpec("0 0 31 2 *")
+	assert.Nil(t, err)
+	assert.True(t, schedule.next(from).IsZero())
+}
+
+func TestParseCronSpec_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every -1s",
+		"@every forever",
+		"@sometimes",
+	} {
+		_, err := parseCronSpec(spec)
+		assert.NotNil(t, err, spec)
+	}
+}
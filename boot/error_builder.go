@@ -0,0 +1,227 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	rkerror "github.com/rookie-ninja/rk-entry/v2/error"
+	rkmid "github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// ErrorModelGoogle error model of google style
+	ErrorModelGoogle = "google"
+	// ErrorModelAmazon error model of amazon style
+	ErrorModelAmazon = "amazon"
+	// ErrorModelRfc7807 error model of RFC 7807 problem details
+	ErrorModelRfc7807 = "rfc7807"
+	// ContentTypeProblemJson Content-Type of RFC 7807 problem details
+	ContentTypeProblemJson = "application/problem+json"
+)
+
+var (
+	errorModels = map[string]rkerror.ErrorBuilder{
+		ErrorModelGoogle:  rkerror.NewErrorBuilderGoogle(),
+		ErrorModelAmazon:  rkerror.NewErrorBuilderAMZN(),
+		ErrorModelRfc7807: NewErrorBuilderProblem(),
+	}
+	errorModelsLock sync.RWMutex
+)
+
+// RegisterErrorModel register error builder with name which could be selected with middleware.errorModel in
+// boot YAML, names are case-insensitive and error builder registered with the same name would be overridden.
+//
+// This function should be called before RegisterGinEntryYAML() called, init() function is recommended.
+// Errors created by builder could implement rkginctx.ErrorWithContentType and rkginctx.ErrorWithRequestScope
+// to customize Content-Type and carry request scoped values.
+func RegisterErrorModel(name string, builder rkerror.ErrorBuilder) {
+	if len(name) < 1 || builder == nil {
+		return
+	}
+
+	errorModelsLock.Lock()
+	defer errorModelsLock.Unlock()
+
+	errorModels[strings.ToLower(name)] = builder
+}
+
+// GetErrorModel returns error builder registered with name, nil if not found.
+func GetErrorModel(name string) rkerror.ErrorBuilder {
+	errorModelsLock.RLock()
+	defer errorModelsLock.RUnlock()
+
+	return errorModels[strings.ToLower(name)]
+}
+
+// ListErrorModels returns names of registered error models in order.
+func ListErrorModels() []string {
+	errorModelsLock.RLock()
+	defer errorModelsLock.RUnlock()
+
+	res := make([]string, 0, len(errorModels))
+	for name := range errorModels {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// ToErrorBuilder returns error builder of ErrorModel, nil if ErrorModel is empty.
+//
+// Error would be returned if ErrorModel was not registered.
+func (boot *BootMiddleware) ToErrorBuilder() (rkerror.ErrorBuilder, error) {
+	if len(boot.ErrorModel) < 1 {
+		return nil, nil
+	}
+
+	builder := GetErrorModel(boot.ErrorModel)
+	if builder == nil {
+		return nil, fmt.Errorf("unknown error model %q in middleware.errorModel, options: %s",
+			boot.ErrorModel, strings.Join(ListErrorModels(), ", "))
+	}
+
+	return builder, nil
+}
+
+// GetErrorBuilder returns error builder of entry, global one of rkmid if missing.
+func (entry *GinEntry) GetErrorBuilder() rkerror.ErrorBuilder {
+	if entry.errorBuilder != nil {
+		return entry.errorBuilder
+	}
+
+	return rkmid.GetErrorBuilder()
+}
+
+// writeError responds with error created by error builder of entry, it is used by handlers outside of Router.
+func (entry *GinEntry) writeError(writer http.ResponseWriter, code int, msg string) {
+	resp := entry.GetErrorBuilder().New(code, msg)
+	contentType := "application/json; charset=utf-8"
+	if typed, ok := resp.(rkginctx.ErrorWithContentType); ok {
+		contentType = typed.ContentType()
+	}
+
+	writer.Header().Set(rkmid.HeaderContentType, contentType)
+	writer.WriteHeader(code)
+	bytes, _ := json.Marshal(resp)
+	writer.Write(bytes)
+}
+
+// injectErrorBuilder is the first middleware of Router which stores error builder of entry into gin.Context,
+// so that middlewares would respond with error model of entry instead of global one.
+func (entry *GinEntry) injectErrorBuilder(ctx *gin.Context) {
+	rkginctx.SetErrorBuilder(ctx, entry.errorBuilder)
+	ctx.Next()
+}
+
+// WithErrorBuilder provide error builder of entry, global one of rkmid would be used if missing.
+func WithErrorBuilder(builder rkerror.ErrorBuilder) GinEntryOption {
+	return func(entry *GinEntry) {
+		if builder != nil {
+			entry.errorBuilder = builder
+		}
+	}
+}
+
+// NewErrorBuilderProblem returns error builder of RFC 7807 problem details.
+func NewErrorBuilderProblem() rkerror.ErrorBuilder {
+	return &ErrorBuilderProblem{}
+}
+
+// ErrorBuilderProblem is error builder of RFC 7807 problem details.
+type ErrorBuilderProblem struct{}
+
+// New creates problem details with status code, message as detail and details as errors.
+func (e *ErrorBuilderProblem) New(code int, msg string, details ...interface{}) rkerror.ErrorInterface {
+	if code < 1 {
+		code = http.StatusInternalServerError
+	}
+
+	resp := &ErrorProblem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: msg,
+		Errors: make([]interface{}, 0),
+	}
+
+	for i := range details {
+		if v, ok := details[i].(error); ok {
+			resp.Errors = append(resp.Errors, v.Error())
+		} else {
+			resp.Errors = append(resp.Errors, details[i])
+		}
+	}
+
+	return resp
+}
+
+// NewCustom creates problem details with internal server error.
+func (e *ErrorBuilderProblem) NewCustom() rkerror.ErrorInterface {
+	return e.New(http.StatusInternalServerError, "")
+}
+
+// ErrorProblem is RFC 7807 problem details with request id and trace id as extension members.
+//
+// Referred RFC 7807: https://www.rfc-editor.org/rfc/rfc7807
+type ErrorProblem struct {
+	Type      string        `json:"type" yaml:"type" example:"about:blank"`
+	Title     string        `json:"title" yaml:"title" example:"Internal Server Error"`
+	Status    int           `json:"status" yaml:"status" example:"500"`
+	Detail    string        `json:"detail,omitempty" yaml:"detail,omitempty" example:"Internal error occurs"`
+	Instance  string        `json:"instance,omitempty" yaml:"instance,omitempty" example:"/v1/greeter"`
+	RequestId string        `json:"requestId,omitempty" yaml:"requestId,omitempty"`
+	TraceId   string        `json:"traceId,omitempty" yaml:"traceId,omitempty"`
+	Errors    []interface{} `json:"errors" yaml:"errors"`
+}
+
+// Code returns status code.
+func (err *ErrorProblem) Code() int {
+	return err.Status
+}
+
+// Message returns detail.
+func (err *ErrorProblem) Message() string {
+	return err.Detail
+}
+
+// Details returns errors.
+func (err *ErrorProblem) Details() []interface{} {
+	return err.Errors
+}
+
+// Error returns string of error
+func (err *ErrorProblem) Error() string {
+	res := "{}"
+
+	if bytes, marshalErr := json.Marshal(err); marshalErr == nil {
+		res = string(bytes)
+	}
+
+	return res
+}
+
+// ContentType returns application/problem+json.
+func (err *ErrorProblem) ContentType() string {
+	return ContentTypeProblemJson
+}
+
+// WithRequestScope returns a copy of problem details with instance, request id and trace id.
+func (err *ErrorProblem) WithRequestScope(instance, requestId, traceId string) rkerror.ErrorInterface {
+	res := *err
+	res.Instance = instance
+	res.RequestId = requestId
+	res.TraceId = traceId
+
+	return &res
+}
@@ -0,0 +1,208 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBootMiddleware_ToErrorBuilder(t *testing.T) {
+	// empty
+	builder, err := (&BootMiddleware{}).ToErrorBuilder()
+	assert.Nil(t, err)
+	assert.Nil(t, builder)
+
+	// unknown
+	_, err = (&BootMiddleware{ErrorModel: "unknown"}).ToErrorBuilder()
+	assert.NotNil(t, err)
+
+	// built-in models
+	builder, err = (&BootMiddleware{ErrorModel: "google"}).ToErrorBuilder()
+	assert.Nil(t, err)
+	assert.IsType(t, &rkerror.ErrorBuilderGoogle{}, builder)
+	builder, _ = (&BootMiddleware{ErrorModel: "Amazon"}).ToErrorBuilder()
+	assert.IsType(t, &rkerror.ErrorBuilderAMZN{}, builder)
+	builder, _ = (&BootMiddleware{ErrorModel: "rfc7807"}).ToErrorBuilder()
+	assert.IsType(t, &ErrorBuilderProblem{}, builder)
+}
+
+func TestRegisterErrorModel(t *testing.T) {
+	// invalid
+	RegisterErrorModel("", rkerror.NewErrorBuilderGoogle())
+	RegisterErrorModel("ut-model", nil)
+	assert.Nil(t, GetErrorModel("ut-model"))
+
+	// happy case
+	RegisterErrorModel("UT-Model", rkerror.NewErrorBuilderAMZN())
+	assert.IsType(t, &rkerror.ErrorBuilderAMZN{}, GetErrorModel("ut-model"))
+	assert.Contains(t, ListErrorModels(), "ut-model")
+	assert.Contains(t, ListErrorModels(), ErrorModelRfc7807)
+}
+
+func TestErrorBuilderProblem(t *testing.T) {
+	builder := NewErrorBuilderProblem()
+
+	// invalid code
+	err := builder.NewCustom()
+	assert.Equal(t, http.StatusInternalServerError, err.Code())
+
+	err = builder.New(http.StatusBadRequest, "ut-message", errors.New("ut-error"), "ut-detail")
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+	assert.Equal(t, "ut-message", err.Message())
+	assert.Equal(t, []interface{}{"ut-error", "ut-detail"}, err.Details())
+	assert.Equal(t, ContentTypeProblemJson, err.(*ErrorProblem).ContentType())
+	assert.Contains(t, err.Error(), `"title":"Bad Request"`)
+
+	// with request scope, original one is not modified
+	scoped := err.(*ErrorProblem).WithRequestScope("/ut", "ut-request-id", "ut-trace-id").(*ErrorProblem)
+	assert.Equal(t, "/ut", scoped.Instance)
+	assert.Equal(t, "ut-request-id", scoped.RequestId)
+	assert.Equal(t, "ut-trace-id", scoped.TraceId)
+	assert.Empty(t, err.(*ErrorProblem).RequestId)
+}
+
+func TestGinEntry_GetErrorBuilder(t *testing.T) {
+	// default
+	entry := RegisterGinEntry(WithName("ut-error-builder-default"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, rkmid.GetErrorBuilder(), entry.GetErrorBuilder())
+	assert.Empty(t, entry.Router.Handlers)
+
+	// with error builder
+	entry = RegisterGinEntry(
+		WithName("ut-error-builder"),
+		WithErrorBuilder(rkerror.NewErrorBuilderAMZN()))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.IsType(t, &rkerror.ErrorBuilderAMZN{}, entry.GetErrorBuilder())
+	assert.Len(t, entry.Router.Handlers, 1)
+}
+
+func TestRegisterGinEntryYAML_WithErrorModelPerEntry(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-error-google
+    port: 1949
+    enabled: true
+    middleware:
+      errorModel: google
+      ignore: ["/ut-ignore"]
+      auth:
+        enabled: true
+        basic: ["user:pass"]
+  - name: ut-error-amazon
+    port: 2008
+    enabled: true
+    middleware:
+      errorModel: amazon
+      auth:
+        enabled: true
+        basic: ["user:pass"]
+`
+	entries := RegisterGinEntryYAML([]byte(bootStr))
+	google := entries["ut-error-google"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(google)
+	amazon := entries["ut-error-amazon"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(amazon)
+
+	// global state is not modified
+	assert.IsType(t, &rkerror.ErrorBuilderGoogle{}, rkmid.GetErrorBuilder())
+	assert.False(t, rkmid.ShouldIgnoreGlobal("/ut-ignore"))
+
+	handler := func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	}
+	google.Router.GET("/ut", handler)
+	google.Router.GET("/ut-ignore", handler)
+	amazon.Router.GET("/ut", handler)
+	amazon.Router.GET("/ut-ignore", handler)
+
+	// google style
+	code, body := serveErrorResp(google, "/ut")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Contains(t, body, "error")
+	assert.NotContains(t, body, "response")
+
+	// amazon style
+	code, body = serveErrorResp(amazon, "/ut")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Contains(t, body, "response")
+	errs := body["response"].(map[string]interface{})["errors"].([]interface{})
+	assert.Len(t, errs, 1)
+
+	// ignored paths are per entry
+	code, _ = serveErrorResp(google, "/ut-ignore")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = serveErrorResp(amazon, "/ut-ignore")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestRegisterGinEntryYAML_WithErrorModelRfc7807(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-error-rfc7807
+    port: 1949
+    enabled: true
+    middleware:
+      errorModel: rfc7807
+      meta:
+        enabled: true
+      auth:
+        enabled: true
+        basic: ["user:pass"]
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-error-rfc7807"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	writer := httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/ut", nil))
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.Equal(t, ContentTypeProblemJson, writer.Header().Get("Content-Type"))
+
+	problem := &ErrorProblem{}
+	assert.Nil(t, json.Unmarshal(writer.Body.Bytes(), problem))
+	assert.Equal(t, http.StatusUnauthorized, problem.Status)
+	assert.Equal(t, "Unauthorized", problem.Title)
+	assert.Equal(t, "/ut", problem.Instance)
+	assert.Equal(t, writer.Header().Get(rkmid.HeaderRequestId), problem.RequestId)
+	assert.NotEmpty(t, problem.RequestId)
+
+	// unknown error model
+	bootStr = `
+gin:
+  - name: ut-error-unknown
+    port: 1949
+    enabled: true
+    middleware:
+      errorModel: unknown
+`
+	defer assertPanic(t)
+	RegisterGinEntryYAML([]byte(bootStr))
+}
+
+func serveErrorResp(entry *GinEntry, path string) (int, map[string]interface{}) {
+	writer := httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, path, nil))
+
+	body := make(map[string]interface{})
+	json.Unmarshal(writer.Body.Bytes(), &body)
+
+	return writer.Code, body
+}
@@ -17,35 +17,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	rkentry "github.com/rookie-ninja/rk-entry/v2/entry"
 	rkerror "github.com/rookie-ninja/rk-entry/v2/error"
-	rkmid "github.com/rookie-ninja/rk-entry/v2/middleware"
-	rkmidauth "github.com/rookie-ninja/rk-entry/v2/middleware/auth"
-	rkmidcors "github.com/rookie-ninja/rk-entry/v2/middleware/cors"
-	rkmidcsrf "github.com/rookie-ninja/rk-entry/v2/middleware/csrf"
-	rkmidjwt "github.com/rookie-ninja/rk-entry/v2/middleware/jwt"
-	rkmidlog "github.com/rookie-ninja/rk-entry/v2/middleware/log"
-	rkmidmeta "github.com/rookie-ninja/rk-entry/v2/middleware/meta"
-	rkmidpanic "github.com/rookie-ninja/rk-entry/v2/middleware/panic"
-	rkmidprom "github.com/rookie-ninja/rk-entry/v2/middleware/prom"
-	rkmidlimit "github.com/rookie-ninja/rk-entry/v2/middleware/ratelimit"
-	rkmidsec "github.com/rookie-ninja/rk-entry/v2/middleware/secure"
-	rkmidtimeout "github.com/rookie-ninja/rk-entry/v2/middleware/timeout"
-	rkmidtrace "github.com/rookie-ninja/rk-entry/v2/middleware/tracing"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/auth"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/cors"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/csrf"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/gzip"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/jwt"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/log"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/meta"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/panic"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/prom"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/ratelimit"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/secure"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/timeout"
-	"github.com/rookie-ninja/rk-gin/v2/middleware/tracing"
 	"github.com/rookie-ninja/rk-query"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"net"
 	"net/http"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -70,59 +47,136 @@ type BootGin struct {
 }
 
 type BootGinElement struct {
-	Enabled       bool                          `yaml:"enabled" json:"enabled"`
-	Name          string                        `yaml:"name" json:"name"`
-	Port          uint64                        `yaml:"port" json:"port"`
-	Description   string                        `yaml:"description" json:"description"`
-	SW            rkentry.BootSW                `yaml:"sw" json:"sw"`
-	Docs          rkentry.BootDocs              `yaml:"docs" json:"docs"`
-	CommonService rkentry.BootCommonService     `yaml:"commonService" json:"commonService"`
-	Prom          rkentry.BootProm              `yaml:"prom" json:"prom"`
-	CertEntry     string                        `yaml:"certEntry" json:"certEntry"`
-	LoggerEntry   string                        `yaml:"loggerEntry" json:"loggerEntry"`
-	EventEntry    string                        `yaml:"eventEntry" json:"eventEntry"`
-	Static        rkentry.BootStaticFileHandler `yaml:"static" json:"static"`
-	PProf         rkentry.BootPProf             `yaml:"pprof" json:"pprof"`
-	Middleware    struct {
-		Ignore     []string                `yaml:"ignore" json:"ignore"`
-		ErrorModel string                  `yaml:"errorModel" json:"errorModel"`
-		Logging    rkmidlog.BootConfig     `yaml:"logging" json:"logging"`
-		Prom       rkmidprom.BootConfig    `yaml:"prom" json:"prom"`
-		Auth       rkmidauth.BootConfig    `yaml:"auth" json:"auth"`
-		Cors       rkmidcors.BootConfig    `yaml:"cors" json:"cors"`
-		Meta       rkmidmeta.BootConfig    `yaml:"meta" json:"meta"`
-		Jwt        rkmidjwt.BootConfig     `yaml:"jwt" json:"jwt"`
-		Secure     rkmidsec.BootConfig     `yaml:"secure" json:"secure"`
-		RateLimit  rkmidlimit.BootConfig   `yaml:"rateLimit" json:"rateLimit"`
-		Csrf       rkmidcsrf.BootConfig    `yaml:"csrf" yaml:"csrf"`
-		Timeout    rkmidtimeout.BootConfig `yaml:"timeout" json:"timeout"`
-		Trace      rkmidtrace.BootConfig   `yaml:"trace" json:"trace"`
-		Gzip       struct {
-			Enabled bool     `yaml:"enabled" json:"enabled"`
-			Ignore  []string `yaml:"ignore" json:"ignore"`
-			Level   string   `yaml:"level" json:"level"`
-		} `yaml:"gzip" json:"gzip"`
-	} `yaml:"middleware" json:"middleware"`
+	Enabled         bool                          `yaml:"enabled" json:"enabled"`
+	Name            string                        `yaml:"name" json:"name"`
+	Port            uint64                        `yaml:"port" json:"port"`
+	Description     string                        `yaml:"description" json:"description"`
+	SW              rkentry.BootSW                `yaml:"sw" json:"sw"`
+	Docs            rkentry.BootDocs              `yaml:"docs" json:"docs"`
+	CommonService   rkentry.BootCommonService     `yaml:"commonService" json:"commonService"`
+	Prom            rkentry.BootProm              `yaml:"prom" json:"prom"`
+	CertEntry       string                        `yaml:"certEntry" json:"certEntry"`
+	LoggerEntry     string                        `yaml:"loggerEntry" json:"loggerEntry"`
+	EventEntry      string                        `yaml:"eventEntry" json:"eventEntry"`
+	Static          rkentry.BootStaticFileHandler `yaml:"static" json:"static"`
+	PProf           rkentry.BootPProf             `yaml:"pprof" json:"pprof"`
+	Hosts           []string                      `yaml:"hosts" json:"hosts"`
+	TrustedProxies  *[]string                     `yaml:"trustedProxies" json:"trustedProxies"`
+	TrustedPlatform string                        `yaml:"trustedPlatform" json:"trustedPlatform"`
+	RemoteIPHeaders []string                      `yaml:"remoteIPHeaders" json:"remoteIPHeaders"`
+	Server          struct {
+		Host                string `yaml:"host" json:"host"`
+		ReadTimeoutMs       int    `yaml:"readTimeoutMs" json:"readTimeoutMs"`
+		ReadHeaderTimeoutMs int    `yaml:"readHeaderTimeoutMs" json:"readHeaderTimeoutMs"`
+		WriteTimeoutMs      int    `yaml:"writeTimeoutMs" json:"writeTimeoutMs"`
+		IdleTimeoutMs       int    `yaml:"idleTimeoutMs" json:"idleTimeoutMs"`
+		MaxHeaderBytes      int    `yaml:"maxHeaderBytes" json:"maxHeaderBytes"`
+	} `yaml:"server" json:"server"`
+	TLS              BootTLS              `yaml:"tls" json:"tls"`
+	Listener         BootListener         `yaml:"listener" json:"listener"`
+	Http2            BootHttp2            `yaml:"http2" json:"http2"`
+	ProxyProtocol    BootProxyProtocol    `yaml:"proxyProtocol" json:"proxyProtocol"`
+	Management       BootManagement       `yaml:"management" json:"management"`
+	Shutdown         BootShutdown         `yaml:"shutdown" json:"shutdown"`
+	GracefulRestart  BootGracefulRestart  `yaml:"gracefulRestart" json:"gracefulRestart"`
+	Middleware       BootMiddleware       `yaml:"middleware" json:"middleware"`
+	RouteGroups      []BootRouteGroup     `yaml:"routeGroups" json:"routeGroups"`
+	Introspection    BootIntrospection    `yaml:"introspection" json:"introspection"`
+	MiddlewareReload BootMiddlewareReload `yaml:"middlewareReload" json:"middlewareReload"`
+	Workers          BootWorkers          `yaml:"workers" json:"workers"`
+
+	// routing options of gin.Engine
+	Mode                   string `yaml:"mode" json:"mode"`
+	HandleMethodNotAllowed bool   `yaml:"handleMethodNotAllowed" json:"handleMethodNotAllowed"`
+	RedirectTrailingSlash  *bool  `yaml:"redirectTrailingSlash" json:"redirectTrailingSlash"`
+	RedirectFixedPath      bool   `yaml:"redirectFixedPath" json:"redirectFixedPath"`
+	RemoveExtraSlash       bool   `yaml:"removeExtraSlash" json:"removeExtraSlash"`
 }
 
 // GinEntry implements rkentry.Entry interface.
 type GinEntry struct {
-	entryName          string                          `json:"-" yaml:"-"`
-	entryType          string                          `json:"-" yaml:"-"`
-	entryDescription   string                          `json:"-" yaml:"-"`
-	Router             *gin.Engine                     `json:"-" yaml:"-"`
-	Server             *http.Server                    `json:"-" yaml:"-"`
-	Port               uint64                          `json:"-" yaml:"-"`
-	LoggerEntry        *rkentry.LoggerEntry            `json:"-" yaml:"-"`
-	EventEntry         *rkentry.EventEntry             `json:"-" yaml:"-"`
-	SwEntry            *rkentry.SWEntry                `json:"-" yaml:"-"`
-	DocsEntry          *rkentry.DocsEntry              `json:"-" yaml:"-"`
-	CommonServiceEntry *rkentry.CommonServiceEntry     `json:"-" yaml:"-"`
-	PromEntry          *rkentry.PromEntry              `json:"-" yaml:"-"`
-	StaticFileEntry    *rkentry.StaticFileHandlerEntry `json:"-" yaml:"-"`
-	CertEntry          *rkentry.CertEntry              `json:"-" yaml:"-"`
-	PProfEntry         *rkentry.PProfEntry             `json:"-" yaml:"-"`
-	bootstrapLogOnce   sync.Once                       `json:"-" yaml:"-"`
+	entryName           string                          `json:"-" yaml:"-"`
+	entryType           string                          `json:"-" yaml:"-"`
+	entryDescription    string                          `json:"-" yaml:"-"`
+	Router              *gin.Engine                     `json:"-" yaml:"-"`
+	Server              *http.Server                    `json:"-" yaml:"-"`
+	Port                uint64                          `json:"-" yaml:"-"`
+	Host                string                          `json:"-" yaml:"-"`
+	LoggerEntry         *rkentry.LoggerEntry            `json:"-" yaml:"-"`
+	EventEntry          *rkentry.EventEntry             `json:"-" yaml:"-"`
+	SwEntry             *rkentry.SWEntry                `json:"-" yaml:"-"`
+	DocsEntry           *rkentry.DocsEntry              `json:"-" yaml:"-"`
+	CommonServiceEntry  *rkentry.CommonServiceEntry     `json:"-" yaml:"-"`
+	PromEntry           *rkentry.PromEntry              `json:"-" yaml:"-"`
+	StaticFileEntry     *rkentry.StaticFileHandlerEntry `json:"-" yaml:"-"`
+	CertEntry           *rkentry.CertEntry              `json:"-" yaml:"-"`
+	PProfEntry          *rkentry.PProfEntry             `json:"-" yaml:"-"`
+	ManagementRouter    *gin.Engine                     `json:"-" yaml:"-"`
+	ManagementServer    *http.Server                    `json:"-" yaml:"-"`
+	ManagementPort      uint64                          `json:"-" yaml:"-"`
+	ManagementHost      string                          `json:"-" yaml:"-"`
+	ManagementCertEntry *rkentry.CertEntry              `json:"-" yaml:"-"`
+	bootstrapLogOnce    sync.Once                       `json:"-" yaml:"-"`
+	readTimeout         time.Duration                   `json:"-" yaml:"-"`
+	readHeaderTimeout   time.Duration                   `json:"-" yaml:"-"`
+	writeTimeout        time.Duration                   `json:"-" yaml:"-"`
+	idleTimeout         time.Duration                   `json:"-" yaml:"-"`
+	maxHeaderBytes      int                             `json:"-" yaml:"-"`
+	clientAuth          tls.ClientAuthType              `json:"-" yaml:"-"`
+	tlsMinVersion       uint16                          `json:"-" yaml:"-"`
+	cipherSuites        []uint16                        `json:"-" yaml:"-"`
+	nextProtos          []string                        `json:"-" yaml:"-"`
+	certReloadInterval  time.Duration                   `json:"-" yaml:"-"`
+	certReloader        *certReloader                   `json:"-" yaml:"-"`
+	certReloaderOnce    sync.Once                       `json:"-" yaml:"-"`
+	mgmtCertReloader    *certReloader                   `json:"-" yaml:"-"`
+	mgmtCertReloadOnce  sync.Once                       `json:"-" yaml:"-"`
+	certNotAfterGauge   *prometheus.GaugeVec            `json:"-" yaml:"-"`
+	preStopDelay        time.Duration                   `json:"-" yaml:"-"`
+	gracePeriod         time.Duration                   `json:"-" yaml:"-"`
+	draining            int32                           `json:"-" yaml:"-"`
+	inFlight            int64                           `json:"-" yaml:"-"`
+	listener            net.Listener                    `json:"-" yaml:"-"`
+	managementListener  net.Listener                    `json:"-" yaml:"-"`
+	customListener      net.Listener                    `json:"-" yaml:"-"`
+	listenerType        string                          `json:"-" yaml:"-"`
+	socketPath          string                          `json:"-" yaml:"-"`
+	socketMode          os.FileMode                     `json:"-" yaml:"-"`
+	listenerFd          int                             `json:"-" yaml:"-"`
+	gracefulRestart     bool                            `json:"-" yaml:"-"`
+	restartReadyTimeout time.Duration                   `json:"-" yaml:"-"`
+	h2c                 bool                            `json:"-" yaml:"-"`
+	http2Server         *http2.Server                   `json:"-" yaml:"-"`
+	proxyProtocol       bool                            `json:"-" yaml:"-"`
+	proxyTrustedCidrs   []*net.IPNet                    `json:"-" yaml:"-"`
+	proxyHeaderTimeout  time.Duration                   `json:"-" yaml:"-"`
+	trustedProxies      []string                        `json:"-" yaml:"-"`
+	trustedPlatform     string                          `json:"-" yaml:"-"`
+	remoteIPHeaders     []string                        `json:"-" yaml:"-"`
+	errorBuilder        rkerror.ErrorBuilder            `json:"-" yaml:"-"`
+	middlewareChain     []string                        `json:"-" yaml:"-"`
+	namedMiddlewares    map[string]*middlewareSlot      `json:"-" yaml:"-"`
+	routeGroups         []*routeGroup                   `json:"-" yaml:"-"`
+	introspection       bool                            `json:"-" yaml:"-"`
+	bootConfig          *BootGinElement                 `json:"-" yaml:"-"`
+	reloadLock          sync.Mutex                      `json:"-" yaml:"-"`
+	reloadPath          string                          `json:"-" yaml:"-"`
+	reloadInterval      time.Duration                   `json:"-" yaml:"-"`
+	middlewareReloader  *middlewareReloader             `json:"-" yaml:"-"`
+	hosts               []string                        `json:"-" yaml:"-"`
+	virtualHost         *virtualHost                    `json:"-" yaml:"-"`
+
+	mode                   string `json:"-" yaml:"-"`
+	handleMethodNotAllowed bool   `json:"-" yaml:"-"`
+	redirectTrailingSlash  bool   `json:"-" yaml:"-"`
+	redirectFixedPath      bool   `json:"-" yaml:"-"`
+	removeExtraSlash       bool   `json:"-" yaml:"-"`
+
+	hookLock sync.Mutex                  `json:"-" yaml:"-"`
+	hooks    map[string][]*lifecycleHook `json:"-" yaml:"-"`
+
+	workerGroup    *workerGroup    `json:"-" yaml:"-"`
+	healthRegistry *healthRegistry `json:"-" yaml:"-"`
 }
 
 // RegisterGinEntryYAML register gin entries with provided config file (Must YAML file).
@@ -146,6 +200,11 @@ type GinEntry struct {
 func RegisterGinEntryYAML(raw []byte) map[string]rkentry.Entry {
 	res := make(map[string]rkentry.Entry)
 
+	// 0: Fail fast with all problems found in boot config
+	if errs := ValidateBootGin(raw); len(errs) > 0 {
+		rkentry.ShutdownWithError(joinValidationErrors(errs))
+	}
+
 	// 1: Decode config map into boot config struct
 	config := &BootGin{}
 	rkentry.UnmarshalBootYAML(raw, config)
@@ -193,118 +252,135 @@ func RegisterGinEntryYAML(raw []byte) map[string]rkentry.Entry {
 		// Register pprof entry
 		pprofEntry := rkentry.RegisterPProfEntry(&element.PProf, rkentry.WithNamePProfEntry(element.Name))
 
-		inters := make([]gin.HandlerFunc, 0)
-
-		// add global path ignorance
-		rkmid.AddPathToIgnoreGlobal(element.Middleware.Ignore...)
-
-		// set error builder based on error builder
-		switch strings.ToLower(element.Middleware.ErrorModel) {
-		case "", "google":
-			rkmid.SetErrorBuilder(rkerror.NewErrorBuilderGoogle())
-		case "amazon":
-			rkmid.SetErrorBuilder(rkerror.NewErrorBuilderAMZN())
+		// error builder of entry
+		errorBuilder, err := element.Middleware.ToErrorBuilder()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// logging middlewares
-		if element.Middleware.Logging.Enabled {
-			inters = append(inters, rkginlog.Middleware(
-				rkmidlog.ToOptions(&element.Middleware.Logging, element.Name, GinEntryType,
-					loggerEntry, eventEntry)...))
+		// middlewares in resolved order, ignored paths are passed to each middleware
+		midNames, mids, err := element.Middleware.ToMiddlewares(element.Name, loggerEntry, eventEntry, promRegistry)
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// Default interceptor should be placed after logging middleware, we should make sure interceptors never panic
-		// insert panic interceptor
-		inters = append(inters, rkginpanic.Middleware(
-			rkmidpanic.WithEntryNameAndType(element.Name, GinEntryType)))
-
-		// metrics middleware
-		if element.Middleware.Prom.Enabled {
-			inters = append(inters, rkginprom.Middleware(
-				rkmidprom.ToOptions(&element.Middleware.Prom, element.Name, GinEntryType,
-					promRegistry, rkmidprom.LabelerTypeHttp)...))
+		// tls options
+		tlsOpts, err := element.TLS.ToOptions()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// tracing middleware
-		if element.Middleware.Trace.Enabled {
-			inters = append(inters, rkgintrace.Middleware(
-				rkmidtrace.ToOptions(&element.Middleware.Trace, element.Name, GinEntryType)...))
+		// listener options
+		listenerOpts, err := element.Listener.ToOptions()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// cors middleware
-		if element.Middleware.Cors.Enabled {
-			inters = append(inters, rkgincors.Middleware(
-				rkmidcors.ToOptions(&element.Middleware.Cors, element.Name, GinEntryType)...))
+		// PROXY protocol options
+		proxyOpts, err := element.ProxyProtocol.ToOptions()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// jwt middleware
-		if element.Middleware.Jwt.Enabled {
-			inters = append(inters, rkginjwt.Middleware(
-				rkmidjwt.ToOptions(&element.Middleware.Jwt, element.Name, GinEntryType)...))
+		// middleware reload options
+		reloadOpts, err := element.MiddlewareReload.ToOptions()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// secure middleware
-		if element.Middleware.Secure.Enabled {
-			inters = append(inters, rkginsec.Middleware(
-				rkmidsec.ToOptions(&element.Middleware.Secure, element.Name, GinEntryType)...))
+		// worker options
+		workerOpts, err := element.Workers.ToOptions()
+		if err != nil {
+			rkentry.ShutdownWithError(err)
 		}
 
-		// csrf middleware
-		if element.Middleware.Csrf.Enabled {
-			inters = append(inters, rkgincsrf.Middleware(
-				rkmidcsrf.ToOptions(&element.Middleware.Csrf, element.Name, GinEntryType)...))
-		}
-
-		// gzip middleware
-		if element.Middleware.Gzip.Enabled {
-			opts := []rkgingzip.Option{
-				rkgingzip.WithEntryNameAndType(element.Name, GinEntryType),
-				rkgingzip.WithLevel(element.Middleware.Gzip.Level),
-				rkgingzip.WithPathToIgnore(element.Middleware.Gzip.Ignore...),
+		// client IP options, validate trusted proxies in advance
+		if element.TrustedProxies != nil {
+			if _, err := ParseCidrs(*element.TrustedProxies...); err != nil {
+				rkentry.ShutdownWithError(err)
 			}
-
-			inters = append(inters, rkgingzip.Middleware(opts...))
-		}
-
-		// meta middleware
-		if element.Middleware.Meta.Enabled {
-			inters = append(inters, rkginmeta.Middleware(
-				rkmidmeta.ToOptions(&element.Middleware.Meta, element.Name, GinEntryType)...))
 		}
 
-		// auth middlewares
-		if element.Middleware.Auth.Enabled {
-			inters = append(inters, rkginauth.Middleware(
-				rkmidauth.ToOptions(&element.Middleware.Auth, element.Name, GinEntryType)...))
-		}
-
-		// timeout middlewares
-		if element.Middleware.Timeout.Enabled {
-			inters = append(inters, rkgintout.Middleware(
-				rkmidtimeout.ToOptions(&element.Middleware.Timeout, element.Name, GinEntryType)...))
-		}
-
-		// rate limit middleware
-		if element.Middleware.RateLimit.Enabled {
-			inters = append(inters, rkginlimit.Middleware(
-				rkmidlimit.ToOptions(&element.Middleware.RateLimit, element.Name, GinEntryType)...))
-		}
-
-		entry := RegisterGinEntry(
+		opts := []GinEntryOption{
 			WithLoggerEntry(loggerEntry),
 			WithEventEntry(eventEntry),
 			WithName(name),
 			WithDescription(element.Description),
 			WithPort(element.Port),
+			WithHost(element.Server.Host),
+			WithReadTimeout(time.Duration(element.Server.ReadTimeoutMs) * time.Millisecond),
+			WithReadHeaderTimeout(time.Duration(element.Server.ReadHeaderTimeoutMs) * time.Millisecond),
+			WithWriteTimeout(time.Duration(element.Server.WriteTimeoutMs) * time.Millisecond),
+			WithIdleTimeout(time.Duration(element.Server.IdleTimeoutMs) * time.Millisecond),
+			WithMaxHeaderBytes(element.Server.MaxHeaderBytes),
+			WithTrustedPlatform(element.TrustedPlatform),
+			WithRemoteIPHeaders(element.RemoteIPHeaders...),
 			WithSwEntry(swEntry),
 			WithDocsEntry(docsEntry),
 			WithPromEntry(promEntry),
 			WithCommonServiceEntry(commonServiceEntry),
 			WithCertEntry(certEntry),
 			WithPProfEntry(pprofEntry),
-			WithStaticFileHandlerEntry(staticEntry))
+			WithStaticFileHandlerEntry(staticEntry),
+			WithManagementPort(element.Management.Port),
+			WithManagementHost(element.Management.Host),
+			WithManagementCertEntry(rkentry.GlobalAppCtx.GetCertEntry(element.Management.CertEntry)),
+			WithPreStopDelay(time.Duration(element.Shutdown.PreStopDelayMs) * time.Millisecond),
+			WithGracePeriod(time.Duration(element.Shutdown.GracePeriodMs) * time.Millisecond),
+			WithErrorBuilder(errorBuilder),
+			WithMode(element.Mode),
+			WithHandleMethodNotAllowed(element.HandleMethodNotAllowed),
+			WithRedirectFixedPath(element.RedirectFixedPath),
+			WithRemoveExtraSlash(element.RemoveExtraSlash),
+		}
+		opts = append(opts, tlsOpts...)
+		opts = append(opts, listenerOpts...)
+		opts = append(opts, element.Http2.ToOptions()...)
+		opts = append(opts, proxyOpts...)
+		opts = append(opts, reloadOpts...)
+		opts = append(opts, workerOpts...)
+
+		// empty list means trusting none, only missing one keeps default of gin
+		if element.TrustedProxies != nil {
+			opts = append(opts, WithTrustedProxies(*element.TrustedProxies...))
+		}
+
+		if element.GracefulRestart.Enabled {
+			opts = append(opts, WithGracefulRestart(time.Duration(element.GracefulRestart.ReadyTimeoutMs)*time.Millisecond))
+		}
+
+		if element.Introspection.Enabled {
+			opts = append(opts, WithIntrospection())
+		}
+
+		if len(element.Hosts) > 0 {
+			opts = append(opts, WithHosts(element.Hosts...))
+		}
+
+		// redirectTrailingSlash is enabled by default
+		if element.RedirectTrailingSlash != nil {
+			opts = append(opts, WithRedirectTrailingSlash(*element.RedirectTrailingSlash))
+		}
+
+		entry := RegisterGinEntry(opts...)
+		entry.bootConfig = element
+
+		for i := range mids {
+			entry.AddNamedMiddleware(midNames[i], mids[i])
+		}
+
+		// route groups with dedicated middlewares
+		for i := range element.RouteGroups {
+			group := &element.RouteGroups[i]
+			groupMidNames, groupMids, err := group.ToMiddlewares(element.Name, loggerEntry, eventEntry)
+			if err != nil {
+				rkentry.ShutdownWithError(err)
+			}
 
-		entry.AddMiddleware(inters...)
+			if err := entry.addRouteGroup(group.Name, group.Path, groupMidNames, groupMids); err != nil {
+				rkentry.ShutdownWithError(err)
+			}
+		}
 
 		res[name] = entry
 	}
@@ -320,6 +396,13 @@ func RegisterGinEntry(opts ...GinEntryOption) *GinEntry {
 		LoggerEntry:      rkentry.NewLoggerEntryStdout(),
 		EventEntry:       rkentry.NewEventEntryStdout(),
 		Port:             80,
+		Host:             "0.0.0.0",
+		ManagementHost:   "0.0.0.0",
+		gracePeriod:      defaultGracePeriod,
+		workerGroup:      newWorkerGroup(),
+		healthRegistry:   newHealthRegistry(),
+		// default of gin.Engine
+		redirectTrailingSlash: true,
 	}
 
 	for i := range opts {
@@ -331,17 +414,36 @@ func RegisterGinEntry(opts ...GinEntryOption) *GinEntry {
 	}
 
 	if entry.Router == nil {
-		gin.SetMode(gin.ReleaseMode)
+		gin.SetMode(entry.resolveMode())
 		entry.Router = gin.New()
 	}
 
-	if entry.Port != 0 {
-		entry.Server = &http.Server{
-			Addr:    "0.0.0.0:" + strconv.FormatUint(entry.Port, 10),
-			Handler: entry.Router,
-		}
+	// routing options, 404 and 405 are rendered with error builder of entry
+	entry.configureRouter()
+
+	// error builder of entry should be injected before any other middlewares
+	if entry.errorBuilder != nil {
+		entry.Router.Use(entry.injectErrorBuilder)
 	}
 
+	// resolve client IP only with headers set by trusted proxies
+	if err := entry.configureClientIP(); err != nil {
+		rkentry.ShutdownWithError(err)
+	}
+
+	// port 0 means ephemeral port chosen by system, use ListenAddr() to get bound address
+	entry.Server = &http.Server{
+		Addr:              net.JoinHostPort(entry.Host, strconv.FormatUint(entry.Port, 10)),
+		Handler:           entry.wrapH2c(entry.trackInFlight(entry.Router)),
+		ReadTimeout:       entry.readTimeout,
+		ReadHeaderTimeout: entry.readHeaderTimeout,
+		WriteTimeout:      entry.writeTimeout,
+		IdleTimeout:       entry.idleTimeout,
+		MaxHeaderBytes:    entry.maxHeaderBytes,
+	}
+
+	entry.initManagement()
+
 	// add entry name and entry type into loki syncer if enabled
 	entry.LoggerEntry.AddEntryLabelToLokiSyncer(entry)
 	entry.EventEntry.AddEntryLabelToLokiSyncer(entry)
@@ -367,30 +469,70 @@ func (entry *GinEntry) GetDescription() string {
 }
 
 // Bootstrap GinEntry.
+//
+// Process will shutdown with rkentry.ShutdownWithError if listener could not be bound, use BootstrapE instead
+// if caller wants to handle the error.
 func (entry *GinEntry) Bootstrap(ctx context.Context) {
+	if err := entry.BootstrapE(ctx); err != nil {
+		rkentry.ShutdownWithError(err)
+	}
+}
+
+// BootstrapE GinEntry and returns error if listeners could not be bound or TLS config is invalid.
+//
+// Listeners are bound synchronously, so server is accepting connections once BootstrapE returns nil.
+func (entry *GinEntry) BootstrapE(ctx context.Context) error {
 	event, logger := entry.logBasicInfo("Bootstrap", ctx)
 
+	// warm up before binding listeners, bootstrap is aborted if any hook fails
+	if err := entry.runHooks(ctx, HookBeforeBootstrap, event, logger, true); err != nil {
+		logger.Error("Error occurs while bootstrapping gin entry.", event.ListPayloads()...)
+		entry.EventEntry.FinishWithCond(event, false)
+		return err
+	}
+
+	// bind listeners before registering anything, so that BootstrapE could be retried
+	ln, mgmtLn, err := entry.listenAll()
+	if err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while binding gin-listener.", event.ListPayloads()...)
+		entry.EventEntry.FinishWithCond(event, false)
+		return err
+	}
+	event.AddPayloads(zap.String("listenAddr", entry.ListenAddr().String()))
+	if len(entry.hosts) > 0 {
+		event.AddPayloads(zap.Strings("hosts", entry.hosts))
+	}
+
+	// builtin endpoints would be registered into management router if dedicated listener enabled
+	router := entry.managementRouter()
+
 	// Is common service enabled?
 	if entry.IsCommonServiceEnabled() {
 		// Register common service path into Router.
-		entry.Router.GET(entry.CommonServiceEntry.ReadyPath, gin.WrapF(entry.CommonServiceEntry.Ready))
-		entry.Router.GET(entry.CommonServiceEntry.AlivePath, gin.WrapF(entry.CommonServiceEntry.Alive))
-		entry.Router.GET(entry.CommonServiceEntry.GcPath, gin.WrapF(entry.CommonServiceEntry.Gc))
-		entry.Router.GET(entry.CommonServiceEntry.InfoPath, gin.WrapF(entry.CommonServiceEntry.Info))
+		router.GET(entry.CommonServiceEntry.ReadyPath, gin.WrapF(entry.ready))
+		router.GET(entry.CommonServiceEntry.AlivePath, gin.WrapF(entry.CommonServiceEntry.Alive))
+		router.GET(entry.CommonServiceEntry.GcPath, gin.WrapF(entry.CommonServiceEntry.Gc))
+		router.GET(entry.CommonServiceEntry.InfoPath, gin.WrapF(entry.CommonServiceEntry.Info))
 
 		// Bootstrap common service entry.
 		entry.CommonServiceEntry.Bootstrap(ctx)
 	}
 
+	// health endpoint reports each health check, readiness combines critical ones
+	if entry.IsCommonServiceEnabled() || len(entry.healthRegistry.checks) > 0 {
+		entry.registerHealth(router)
+	}
+
 	// Is swagger enabled?
 	if entry.IsSwEnabled() {
-		entry.Router.GET(path.Join(entry.SwEntry.Path, "*any"), gin.WrapF(entry.SwEntry.ConfigFileHandler()))
+		router.GET(path.Join(entry.SwEntry.Path, "*any"), gin.WrapF(entry.SwEntry.ConfigFileHandler()))
 		entry.SwEntry.Bootstrap(ctx)
 	}
 
 	// Is docs enabled?
 	if entry.IsDocsEnabled() {
-		entry.Router.GET(path.Join(entry.DocsEntry.Path, "*any"), gin.WrapF(entry.DocsEntry.ConfigFileHandler()))
+		router.GET(path.Join(entry.DocsEntry.Path, "*any"), gin.WrapF(entry.DocsEntry.ConfigFileHandler()))
 		entry.DocsEntry.Bootstrap(ctx)
 	}
 
@@ -403,17 +545,66 @@ func (entry *GinEntry) Bootstrap(ctx context.Context) {
 	// Is prometheus enabled?
 	if entry.IsPromEnabled() {
 		// Register prom path into Router.
-		entry.Router.GET(entry.PromEntry.Path, gin.WrapH(promhttp.HandlerFor(entry.PromEntry.Gatherer, promhttp.HandlerOpts{})))
+		router.GET(entry.PromEntry.Path, gin.WrapH(promhttp.HandlerFor(entry.PromEntry.Gatherer, promhttp.HandlerOpts{})))
 		entry.PromEntry.Bootstrap(ctx)
 	}
 
 	// Is pprof enabled?
 	if entry.IsPProfEnabled() {
-		pprof.Register(entry.Router, entry.PProfEntry.Path)
+		pprof.Register(router, entry.PProfEntry.Path)
+	}
+
+	// Is introspection enabled?
+	if entry.IsIntrospectionEnabled() {
+		entry.registerIntrospection(router)
 	}
 
-	// Start gin server
-	go entry.startServer(event, logger)
+	// Is TLS enabled?
+	if entry.IsTlsEnabled() || entry.IsManagementTlsEnabled() {
+		entry.startCertReloader()
+	}
+
+	// Is middleware reload enabled?
+	if entry.IsMiddlewareReloadEnabled() {
+		entry.startMiddlewareReloader()
+	}
+
+	// export in-flight requests and results of health checks
+	entry.registerInFlightGauge()
+	entry.registerHealthGauges()
+
+	// routes could be registered by hooks before server starts serving, errors are recorded in event only
+	entry.runHooks(ctx, HookAfterListen, event, logger, false)
+
+	// Start gin server, listener of virtual host is served by the entry which bound it
+	if ln != nil {
+		go func() {
+			if err := entry.serve(ln); err != nil && err != http.ErrServerClosed {
+				logger.Error("Error occurs while serving gin-listener.", zap.Error(err))
+			}
+		}()
+	}
+
+	// Start management server
+	if mgmtLn != nil {
+		go func() {
+			if err := entry.serveManagement(mgmtLn); err != nil && err != http.ErrServerClosed {
+				logger.Error("Error occurs while serving gin-management-listener.", zap.Error(err))
+			}
+		}()
+	}
+
+	// Start background workers
+	entry.startWorkers()
+	if workers := entry.ListWorkers(); len(workers) > 0 {
+		event.AddPayloads(zap.Strings("workers", workers))
+	}
+
+	// notify parent process if listeners were inherited, and watch SIGUSR2 if graceful restart enabled
+	inherited.markServed(entry.listenerName(), entry.managementListenerName())
+	if entry.IsGracefulRestartEnabled() {
+		gracefulRestarter.add(entry)
+	}
 
 	entry.bootstrapLogOnce.Do(func() {
 		// Print link and logging message
@@ -421,39 +612,79 @@ func (entry *GinEntry) Bootstrap(ctx context.Context) {
 		if entry.IsTlsEnabled() {
 			scheme = "https"
 		}
+		mgmtScheme, mgmtPort := entry.managementAddr()
 
 		if entry.IsSwEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("SwaggerEntry: %s://localhost:%d%s", scheme, entry.Port, entry.SwEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("SwaggerEntry: %s://localhost:%d%s", mgmtScheme, mgmtPort, entry.SwEntry.Path))
 		}
 		if entry.IsDocsEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("DocsEntry: %s://localhost:%d%s", scheme, entry.Port, entry.DocsEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("DocsEntry: %s://localhost:%d%s", mgmtScheme, mgmtPort, entry.DocsEntry.Path))
 		}
 		if entry.IsPromEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("PromEntry: %s://localhost:%d%s", scheme, entry.Port, entry.PromEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("PromEntry: %s://localhost:%d%s", mgmtScheme, mgmtPort, entry.PromEntry.Path))
 		}
 		if entry.IsStaticFileHandlerEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("StaticFileHandlerEntry: %s://localhost:%d%s", scheme, entry.Port, entry.StaticFileEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("StaticFileHandlerEntry: %s://localhost:%d%s", scheme, entry.ListenPort(), entry.StaticFileEntry.Path))
 		}
 		if entry.IsCommonServiceEnabled() {
 			handlers := []string{
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.ReadyPath),
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.AlivePath),
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.InfoPath),
+				fmt.Sprintf("%s://localhost:%d%s", mgmtScheme, mgmtPort, entry.CommonServiceEntry.ReadyPath),
+				fmt.Sprintf("%s://localhost:%d%s", mgmtScheme, mgmtPort, entry.CommonServiceEntry.AlivePath),
+				fmt.Sprintf("%s://localhost:%d%s", mgmtScheme, mgmtPort, entry.CommonServiceEntry.InfoPath),
 			}
 
 			entry.LoggerEntry.Info(fmt.Sprintf("CommonSreviceEntry: %s", strings.Join(handlers, ", ")))
 		}
 		if entry.IsPProfEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("PProfEntry: %s://localhost:%d%s", scheme, entry.Port, entry.PProfEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("PProfEntry: %s://localhost:%d%s", mgmtScheme, mgmtPort, entry.PProfEntry.Path))
 		}
 		entry.EventEntry.Finish(event)
 	})
+
+	return nil
+}
+
+// listenAll binds listeners of gin server and management server.
+func (entry *GinEntry) listenAll() (net.Listener, net.Listener, error) {
+	ln, err := entry.listen()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry.ManagementServer == nil {
+		return ln, nil, nil
+	}
+
+	mgmtLn, err := entry.listenManagement()
+	if err != nil {
+		// listener of virtual host is closed only if no other entry serves on it
+		if entry.virtualHost != nil && entry.leaveVirtualHost() == nil {
+			return nil, nil, err
+		}
+
+		// listener provided by WithListener() is owned by caller until bootstrapped
+		if entry.listener != entry.customListener {
+			entry.listener.Close()
+		}
+		entry.listener = nil
+		return nil, nil, err
+	}
+
+	return ln, mgmtLn, nil
 }
 
 // Interrupt GinEntry.
 func (entry *GinEntry) Interrupt(ctx context.Context) {
 	event, logger := entry.logBasicInfo("Interrupt", ctx)
 
+	gracefulRestarter.remove(entry)
+
+	// flip readiness and wait for load balancers to stop routing traffic
+	entry.startDraining(ctx)
+	event.AddPayloads(zap.Int64("inFlightRequestsBeforeDrain", entry.InFlightRequests()))
+
+	entry.runHooks(ctx, HookBeforeShutdown, event, logger, false)
+
 	if entry.IsStaticFileHandlerEnabled() {
 		// Interrupt entry
 		entry.StaticFileEntry.Interrupt(ctx)
@@ -482,16 +713,54 @@ func (entry *GinEntry) Interrupt(ctx context.Context) {
 		entry.PProfEntry.Interrupt(ctx)
 	}
 
+	if entry.certReloader != nil {
+		entry.certReloader.stop()
+	}
+
+	if entry.mgmtCertReloader != nil {
+		entry.mgmtCertReloader.stop()
+	}
+
+	if entry.middlewareReloader != nil {
+		entry.middlewareReloader.stop()
+	}
+
+	// gin server and management server share grace period, the latter gets what remains of it
+	shutdownCtx, cancel := context.WithTimeout(ctx, entry.gracePeriod)
+	defer cancel()
+
 	if entry.Router != nil && entry.Server != nil {
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
+		// server of virtual host is stopped by the last entry on it, others only drain their own requests
+		server := entry.Server
+		if entry.virtualHost != nil {
+			server = entry.leaveVirtualHost()
+		}
 
-		if err := entry.Server.Shutdown(ctx); err != nil {
+		if server == nil {
+			entry.waitInFlight(shutdownCtx)
+		} else if err := server.Shutdown(shutdownCtx); err != nil {
 			event.AddErr(err)
 			logger.Warn("Error occurs while stopping gin-server.", event.ListPayloads()...)
 		}
 	}
 
+	event.AddPayloads(zap.Int64("inFlightRequestsAfterDrain", entry.InFlightRequests()))
+
+	if entry.ManagementServer != nil {
+		if err := entry.ManagementServer.Shutdown(shutdownCtx); err != nil {
+			event.AddErr(err)
+			logger.Warn("Error occurs while stopping gin-management-server.", event.ListPayloads()...)
+		}
+	}
+
+	// cancel workers after server stopped, so that requests in flight could still rely on them
+	if err := entry.stopWorkers(ctx); err != nil {
+		event.AddErr(err)
+		logger.Warn("Error occurs while stopping workers.", event.ListPayloads()...)
+	}
+
+	entry.runHooks(ctx, HookAfterShutdown, event, logger, false)
+
 	entry.EventEntry.Finish(event)
 
 	rkentry.GlobalAppCtx.RemoveEntry(entry)
@@ -522,6 +791,14 @@ func (entry *GinEntry) MarshalJSON() ([]byte, error) {
 		"type":                   entry.entryType,
 		"description":            entry.entryDescription,
 		"port":                   entry.Port,
+		"host":                   entry.Host,
+		"readTimeoutMs":          entry.readTimeout.Milliseconds(),
+		"readHeaderTimeoutMs":    entry.readHeaderTimeout.Milliseconds(),
+		"writeTimeoutMs":         entry.writeTimeout.Milliseconds(),
+		"idleTimeoutMs":          entry.idleTimeout.Milliseconds(),
+		"maxHeaderBytes":         entry.maxHeaderBytes,
+		"preStopDelayMs":         entry.preStopDelay.Milliseconds(),
+		"gracePeriodMs":          entry.gracePeriod.Milliseconds(),
 		"swEntry":                entry.SwEntry,
 		"docsEntry":              entry.DocsEntry,
 		"commonServiceEntry":     entry.CommonServiceEntry,
@@ -530,8 +807,81 @@ func (entry *GinEntry) MarshalJSON() ([]byte, error) {
 		"pprofEntry":             entry.PProfEntry,
 	}
 
+	if addr := entry.ListenAddr(); addr != nil {
+		m["listenAddr"] = addr.String()
+	}
+
+	switch entry.listenerType {
+	case ListenerTypeUnix:
+		m["listenerType"] = entry.listenerType
+		m["socketPath"] = entry.socketPath
+	case ListenerTypeFd, ListenerTypeSystemd:
+		m["listenerType"] = entry.listenerType
+		m["listenerFd"] = entry.listenerFd
+	}
+
+	if entry.http2Server != nil {
+		m["http2"] = map[string]interface{}{
+			"h2c":                  entry.h2c,
+			"maxConcurrentStreams": entry.http2Server.MaxConcurrentStreams,
+			"maxReadFrameSize":     entry.http2Server.MaxReadFrameSize,
+			"idleTimeoutMs":        entry.http2Server.IdleTimeout.Milliseconds(),
+		}
+	}
+
+	if len(entry.middlewareChain) > 0 {
+		m["middlewareChain"] = entry.middlewareChain
+	}
+
+	if len(entry.routeGroups) > 0 {
+		groups := make([]map[string]interface{}, 0, len(entry.routeGroups))
+		for _, group := range entry.routeGroups {
+			groups = append(groups, map[string]interface{}{
+				"name":            group.name,
+				"path":            group.path,
+				"middlewareChain": group.chain,
+			})
+		}
+		m["routeGroups"] = groups
+	}
+
+	if entry.trustedProxies != nil {
+		m["trustedProxies"] = entry.trustedProxies
+	}
+
+	if len(entry.trustedPlatform) > 0 {
+		m["trustedPlatform"] = entry.trustedPlatform
+	}
+
+	if len(entry.remoteIPHeaders) > 0 {
+		m["remoteIPHeaders"] = entry.remoteIPHeaders
+	}
+
+	if entry.IsProxyProtocolEnabled() {
+		trusted := make([]string, 0, len(entry.proxyTrustedCidrs))
+		for i := range entry.proxyTrustedCidrs {
+			trusted = append(trusted, entry.proxyTrustedCidrs[i].String())
+		}
+		m["proxyProtocol"] = map[string]interface{}{
+			"trustedCidrs":    trusted,
+			"headerTimeoutMs": entry.proxyHeaderTimeout.Milliseconds(),
+		}
+	}
+
+	if entry.IsGracefulRestartEnabled() {
+		m["gracefulRestartReadyTimeoutMs"] = entry.restartReadyTimeout.Milliseconds()
+	}
+
+	if entry.IsManagementEnabled() {
+		m["managementPort"] = entry.ManagementPort
+		m["managementHost"] = entry.ManagementHost
+		m["managementCertEntry"] = entry.ManagementCertEntry
+	}
+
 	if entry.IsTlsEnabled() {
 		m["certEntry"] = entry.CertEntry
+		m["tlsClientAuth"] = ClientAuthToString(entry.clientAuth)
+		m["tlsCertReloadIntervalMs"] = entry.certReloadInterval.Milliseconds()
 	}
 
 	return json.Marshal(&m)
@@ -619,7 +969,31 @@ func (entry *GinEntry) logBasicInfo(operation string, ctx context.Context) (rkqu
 
 	// add general info
 	event.AddPayloads(
-		zap.Uint64("ginPort", entry.Port))
+		zap.Uint64("ginPort", entry.Port),
+		zap.String("ginHost", entry.Host),
+		zap.Int64("readTimeoutMs", entry.readTimeout.Milliseconds()),
+		zap.Int64("readHeaderTimeoutMs", entry.readHeaderTimeout.Milliseconds()),
+		zap.Int64("writeTimeoutMs", entry.writeTimeout.Milliseconds()),
+		zap.Int64("idleTimeoutMs", entry.idleTimeout.Milliseconds()),
+		zap.Int("maxHeaderBytes", entry.maxHeaderBytes))
+
+	// add management listener info
+	if entry.IsManagementEnabled() {
+		event.AddPayloads(
+			zap.Bool("managementEnabled", true),
+			zap.Uint64("managementPort", entry.ManagementPort),
+			zap.String("managementHost", entry.ManagementHost))
+	}
+
+	// add HTTP/2 info
+	if entry.IsH2cEnabled() {
+		event.AddPayloads(zap.Bool("h2cEnabled", true))
+	}
+
+	// add PROXY protocol info
+	if entry.IsProxyProtocolEnabled() {
+		event.AddPayloads(zap.Bool("proxyProtocolEnabled", true))
+	}
 
 	// add SwEntry info
 	if entry.IsSwEnabled() {
@@ -643,9 +1017,10 @@ func (entry *GinEntry) logBasicInfo(operation string, ctx context.Context) (rkqu
 
 	// add PromEntry info
 	if entry.IsPromEnabled() {
+		_, promPort := entry.managementAddr()
 		event.AddPayloads(
 			zap.Bool("promEnabled", true),
-			zap.Uint64("promPort", entry.Port),
+			zap.Uint64("promPort", promPort),
 			zap.String("promPath", entry.PromEntry.Path))
 	}
 
@@ -666,7 +1041,8 @@ func (entry *GinEntry) logBasicInfo(operation string, ctx context.Context) (rkqu
 	// add tls info
 	if entry.IsTlsEnabled() {
 		event.AddPayloads(
-			zap.Bool("tlsEnabled", true))
+			zap.Bool("tlsEnabled", true),
+			zap.String("tlsClientAuth", ClientAuthToString(entry.clientAuth)))
 	}
 
 	logger.Info(fmt.Sprintf("%s GinEntry", operation))
@@ -674,31 +1050,20 @@ func (entry *GinEntry) logBasicInfo(operation string, ctx context.Context) (rkqu
 	return event, logger
 }
 
-// Start server
-// We move the code here for testability
-func (entry *GinEntry) startServer(event rkquery.Event, logger *zap.Logger) {
-	if entry.Server != nil {
-		// If TLS was enabled, we need to load server certificate and key and start http server with ListenAndServeTLS()
-		if entry.IsTlsEnabled() {
-			entry.Server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{*entry.CertEntry.Certificate}}
+// registerCollector registers prometheus collector into registry of PromEntry if enabled.
+// Collector already registered would be returned if exists.
+func (entry *GinEntry) registerCollector(c prometheus.Collector) prometheus.Collector {
+	if !entry.IsPromEnabled() || entry.PromEntry.Registerer == nil {
+		return c
+	}
 
-			if err := entry.Server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
-				logger.Error("Error occurs while serving gin-listener-tls.", event.ListPayloads()...)
-				entry.bootstrapLogOnce.Do(func() {
-					entry.EventEntry.FinishWithCond(event, false)
-				})
-				rkentry.ShutdownWithError(err)
-			}
-		} else {
-			if err := entry.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				logger.Error("Error occurs while serving gin-listener.", event.ListPayloads()...)
-				entry.bootstrapLogOnce.Do(func() {
-					entry.EventEntry.FinishWithCond(event, false)
-				})
-				rkentry.ShutdownWithError(err)
-			}
+	if err := entry.PromEntry.Registerer.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
 		}
 	}
+
+	return c
 }
 
 // ***************** Options *****************
@@ -772,6 +1137,60 @@ func WithPort(port uint64) GinEntryOption {
 	}
 }
 
+// WithHost provide host which server would bind to.
+func WithHost(host string) GinEntryOption {
+	return func(entry *GinEntry) {
+		if len(host) > 0 {
+			entry.Host = host
+		}
+	}
+}
+
+// WithReadTimeout provide http.Server ReadTimeout.
+func WithReadTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if timeout > 0 {
+			entry.readTimeout = timeout
+		}
+	}
+}
+
+// WithReadHeaderTimeout provide http.Server ReadHeaderTimeout.
+func WithReadHeaderTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if timeout > 0 {
+			entry.readHeaderTimeout = timeout
+		}
+	}
+}
+
+// WithWriteTimeout provide http.Server WriteTimeout.
+func WithWriteTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if timeout > 0 {
+			entry.writeTimeout = timeout
+		}
+	}
+}
+
+// WithIdleTimeout provide http.Server IdleTimeout.
+func WithIdleTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if timeout > 0 {
+			entry.idleTimeout = timeout
+		}
+	}
+}
+
+// WithMaxHeaderBytes provide http.Server MaxHeaderBytes.
+func WithMaxHeaderBytes(size int) GinEntryOption {
+	return func(entry *GinEntry) {
+		if size > 0 {
+			entry.maxHeaderBytes = size
+		}
+	}
+}
+
 // WithName provide name.
 func WithName(name string) GinEntryOption {
 	return func(entry *GinEntry) {
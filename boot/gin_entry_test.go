@@ -15,6 +15,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
@@ -164,6 +165,64 @@ func TestRegisterGinEntry(t *testing.T) {
 	assert.Nil(t, entry.UnmarshalJSON([]byte{}))
 }
 
+func TestRegisterGinEntry_WithServerOptions(t *testing.T) {
+	entry := RegisterGinEntry(
+		WithName("ut-server"),
+		WithPort(8080),
+		WithHost("127.0.0.1"),
+		WithReadTimeout(time.Second),
+		WithReadHeaderTimeout(2*time.Second),
+		WithWriteTimeout(3*time.Second),
+		WithIdleTimeout(4*time.Second),
+		WithMaxHeaderBytes(1024))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.Equal(t, "127.0.0.1:8080", entry.Server.Addr)
+	assert.Equal(t, time.Second, entry.Server.ReadTimeout)
+	assert.Equal(t, 2*time.Second, entry.Server.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, entry.Server.WriteTimeout)
+	assert.Equal(t, 4*time.Second, entry.Server.IdleTimeout)
+	assert.Equal(t, 1024, entry.Server.MaxHeaderBytes)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(entry.String()), &m))
+	assert.Equal(t, "127.0.0.1", m["host"])
+	assert.EqualValues(t, 1000, m["readTimeoutMs"])
+	assert.EqualValues(t, 1024, m["maxHeaderBytes"])
+
+	// default host
+	entry = RegisterGinEntry(WithName("ut-server-default"), WithPort(8080))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, "0.0.0.0:8080", entry.Server.Addr)
+	assert.Zero(t, entry.Server.ReadTimeout)
+}
+
+func TestRegisterGinEntryYAML_WithServer(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-server-yaml
+    port: 1949
+    enabled: true
+    server:
+      host: 127.0.0.1
+      readTimeoutMs: 1000
+      readHeaderTimeoutMs: 2000
+      writeTimeoutMs: 3000
+      idleTimeoutMs: 4000
+      maxHeaderBytes: 2048
+`
+	entries := RegisterGinEntryYAML([]byte(bootStr))
+	entry := entries["ut-server-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.Equal(t, "127.0.0.1:1949", entry.Server.Addr)
+	assert.Equal(t, time.Second, entry.Server.ReadTimeout)
+	assert.Equal(t, 2*time.Second, entry.Server.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, entry.Server.WriteTimeout)
+	assert.Equal(t, 4*time.Second, entry.Server.IdleTimeout)
+	assert.Equal(t, 2048, entry.Server.MaxHeaderBytes)
+}
+
 func TestGinEntry_AddInterceptor(t *testing.T) {
 	defer assertNotPanic(t)
 	entry := RegisterGinEntry()
@@ -211,9 +270,7 @@ func TestGinEntry_Bootstrap(t *testing.T) {
 	entry.Interrupt(context.TODO())
 }
 
-func TestGinEntry_startServer_TlsServerFail(t *testing.T) {
-	defer assertPanic(t)
-
+func TestGinEntry_BootstrapE_TlsServerFail(t *testing.T) {
 	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
 		Cert: []*rkentry.BootCertE{
 			{
@@ -226,26 +283,24 @@ func TestGinEntry_startServer_TlsServerFail(t *testing.T) {
 
 	// let's give an invalid port
 	entry := RegisterGinEntry(
+		WithName("ut-tls-server-fail"),
 		WithPort(808080),
 		WithCertEntry(certEntry))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
 
-	event := rkentry.EventEntryNoop.EventFactory.CreateEventNoop()
-	logger := rkentry.LoggerEntryNoop.Logger
-
-	entry.startServer(event, logger)
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.ListenAddr())
 }
 
-func TestGinEntry_startServer_ServerFail(t *testing.T) {
-	defer assertPanic(t)
-
+func TestGinEntry_BootstrapE_ServerFail(t *testing.T) {
 	// let's give an invalid port
 	entry := RegisterGinEntry(
+		WithName("ut-server-fail"),
 		WithPort(808080))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
 
-	event := rkentry.EventEntryNoop.EventFactory.CreateEventNoop()
-	logger := rkentry.LoggerEntryNoop.Logger
-
-	entry.startServer(event, logger)
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.ListenAddr())
 }
 
 func TestRegisterGinEntriesWithConfig(t *testing.T) {
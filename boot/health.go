@@ -0,0 +1,315 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HealthStatusUp all checks passed
+	HealthStatusUp = "UP"
+	// HealthStatusDegraded only non-critical checks failed
+	HealthStatusDegraded = "DEGRADED"
+	// HealthStatusDown critical checks failed
+	HealthStatusDown = "DOWN"
+
+	// defaultHealthCheckTimeout timeout of health check if missing
+	defaultHealthCheckTimeout = 2 * time.Second
+)
+
+// HealthCheck checks health of a dependency, like database or cache, ctx is cancelled once timeout exceeded.
+type HealthCheck func(ctx context.Context) error
+
+// HealthCheckOption option of health check.
+type HealthCheckOption func(*healthCheck)
+
+// WithHealthCheckNonCritical mark check as non-critical, failure of it degrades health but does not fail readiness.
+func WithHealthCheckNonCritical() HealthCheckOption {
+	return func(check *healthCheck) {
+		check.critical = false
+	}
+}
+
+// WithHealthCheckTimeout provide timeout of check, 2 seconds by default.
+func WithHealthCheckTimeout(timeout time.Duration) HealthCheckOption {
+	return func(check *healthCheck) {
+		if timeout > 0 {
+			check.timeout = timeout
+		}
+	}
+}
+
+// WithHealthCheckCacheInterval reuse result of check within interval, check runs on every request by default.
+func WithHealthCheckCacheInterval(interval time.Duration) HealthCheckOption {
+	return func(check *healthCheck) {
+		if interval > 0 {
+			check.cacheInterval = interval
+		}
+	}
+}
+
+// WithHealthCheckStartupOnly stop running check once it passed, like waiting for migrations or cache warm up.
+func WithHealthCheckStartupOnly() HealthCheckOption {
+	return func(check *healthCheck) {
+		check.startupOnly = true
+	}
+}
+
+// HealthResp is health of entry with status of each component.
+type HealthResp struct {
+	Status     string                 `json:"status" yaml:"status" example:"UP"`
+	Components []*HealthComponentResp `json:"components" yaml:"components"`
+}
+
+// HealthComponentResp is result of a health check.
+type HealthComponentResp struct {
+	Name        string    `json:"name" yaml:"name"`
+	Status      string    `json:"status" yaml:"status" example:"UP"`
+	Critical    bool      `json:"critical" yaml:"critical"`
+	StartupOnly bool      `json:"startupOnly" yaml:"startupOnly"`
+	Cached      bool      `json:"cached" yaml:"cached"`
+	LatencyMs   float64   `json:"latencyMs" yaml:"latencyMs"`
+	CheckedAt   time.Time `json:"checkedAt" yaml:"checkedAt"`
+	Error       string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RegisterHealthCheck registers health check of a dependency with name.
+//
+// Checks are critical by default, readiness of entry fails if any critical check fails. Results are served at
+// <commonService.pathPrefix>/gin/health and exported as rk_gin_health_check_status and
+// rk_gin_health_check_latency_seconds if prom is enabled.
+func (entry *GinEntry) RegisterHealthCheck(name string, check HealthCheck, opts ...HealthCheckOption) error {
+	if len(strings.TrimSpace(name)) < 1 {
+		return errors.New("empty name of health check")
+	}
+
+	if check == nil {
+		return fmt.Errorf("nil function of health check %q", name)
+	}
+
+	c := &healthCheck{
+		name:     name,
+		fn:       check,
+		critical: true,
+		timeout:  defaultHealthCheckTimeout,
+	}
+	for i := range opts {
+		opts[i](c)
+	}
+
+	r := entry.healthRegistry
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			return fmt.Errorf("duplicate health check %q of gin entry %q", name, entry.entryName)
+		}
+	}
+	r.checks = append(r.checks, c)
+
+	return nil
+}
+
+// HealthPath returns path of health endpoint under prefix of common service.
+func (entry *GinEntry) HealthPath() string {
+	prefix := defaultCommonServicePrefix
+	if entry.IsCommonServiceEnabled() {
+		prefix = path.Dir(entry.CommonServiceEntry.ReadyPath)
+	}
+
+	return path.Join(prefix, "gin", "health")
+}
+
+// CheckHealth runs health checks concurrently, results within cache interval and of passed startup-only checks
+// are reused.
+//
+// Status is DOWN if any critical check failed, DEGRADED if only non-critical checks failed, UP otherwise.
+func (entry *GinEntry) CheckHealth(ctx context.Context) *HealthResp {
+	res := &HealthResp{
+		Status:     HealthStatusUp,
+		Components: entry.runHealthChecks(ctx, false),
+	}
+
+	for _, component := range res.Components {
+		if component.Status == HealthStatusUp {
+			continue
+		}
+
+		if component.Critical {
+			res.Status = HealthStatusDown
+		} else if res.Status == HealthStatusUp {
+			res.Status = HealthStatusDegraded
+		}
+	}
+
+	return res
+}
+
+// registerHealth registers health endpoint into router.
+func (entry *GinEntry) registerHealth(router *gin.Engine) {
+	router.GET(entry.HealthPath(), entry.health)
+}
+
+// health responds with results of health checks, 503 if any critical check failed.
+func (entry *GinEntry) health(ctx *gin.Context) {
+	res := entry.CheckHealth(ctx.Request.Context())
+
+	code := http.StatusOK
+	if res.Status == HealthStatusDown {
+		code = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(code, res)
+}
+
+// failedCriticalChecks runs critical health checks only and returns names of failed ones.
+func (entry *GinEntry) failedCriticalChecks(ctx context.Context) []string {
+	res := make([]string, 0)
+	for _, component := range entry.runHealthChecks(ctx, true) {
+		if component.Status != HealthStatusUp {
+			res = append(res, component.Name)
+		}
+	}
+
+	return res
+}
+
+// runHealthChecks runs health checks concurrently, non-critical checks are skipped if criticalOnly is true.
+func (entry *GinEntry) runHealthChecks(ctx context.Context, criticalOnly bool) []*HealthComponentResp {
+	r := entry.healthRegistry
+	r.lock.Lock()
+	checks := make([]*healthCheck, 0, len(r.checks))
+	for i := range r.checks {
+		if r.checks[i].critical || !criticalOnly {
+			checks = append(checks, r.checks[i])
+		}
+	}
+	r.lock.Unlock()
+
+	res := make([]*HealthComponentResp, len(checks))
+	wait := sync.WaitGroup{}
+	for i := range checks {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			res[i] = checks[i].check(ctx, entry)
+		}(i)
+	}
+	wait.Wait()
+
+	return res
+}
+
+// registerHealthGauges exports results of health checks to PromEntry.
+func (entry *GinEntry) registerHealthGauges() {
+	r := entry.healthRegistry
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.status, _ = entry.registerCollector(r.status).(*prometheus.GaugeVec)
+	r.latency, _ = entry.registerCollector(r.latency).(*prometheus.GaugeVec)
+}
+
+// healthRegistry holds health checks of entry.
+type healthRegistry struct {
+	lock    sync.Mutex
+	checks  []*healthCheck
+	status  *prometheus.GaugeVec
+	latency *prometheus.GaugeVec
+}
+
+// newHealthRegistry creates healthRegistry with gauges which are registered on Bootstrap.
+func newHealthRegistry() *healthRegistry {
+	return &healthRegistry{
+		checks: make([]*healthCheck, 0),
+		status: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "rk",
+			Subsystem: "gin",
+			Name:      "health_check_status",
+			Help:      "Result of health check, 1 means passed and 0 means failed.",
+		}, []string{"entryName", "component", "critical"}),
+		latency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "rk",
+			Subsystem: "gin",
+			Name:      "health_check_latency_seconds",
+			Help:      "Latency of the last health check in seconds.",
+		}, []string{"entryName", "component"}),
+	}
+}
+
+// healthCheck is check registered with options and its last result.
+type healthCheck struct {
+	name          string
+	fn            HealthCheck
+	critical      bool
+	timeout       time.Duration
+	cacheInterval time.Duration
+	startupOnly   bool
+	// lock is held while checking, so that concurrent requests reuse the same result if cached
+	lock sync.Mutex
+	last *HealthComponentResp
+}
+
+// check returns cached result if valid, runs check otherwise.
+func (c *healthCheck) check(ctx context.Context, entry *GinEntry) *HealthComponentResp {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.last != nil {
+		passedOnStartup := c.startupOnly && c.last.Status == HealthStatusUp
+		if passedOnStartup || (c.cacheInterval > 0 && time.Since(c.last.CheckedAt) < c.cacheInterval) {
+			res := *c.last
+			res.Cached = true
+			return &res
+		}
+	}
+
+	start := time.Now()
+	err := callWithTimeout(ctx, c.timeout, c.fn)
+	elapsed := time.Since(start)
+
+	res := &HealthComponentResp{
+		Name:        c.name,
+		Status:      HealthStatusUp,
+		Critical:    c.critical,
+		StartupOnly: c.startupOnly,
+		LatencyMs:   float64(elapsed.Microseconds()) / 1000,
+		CheckedAt:   start,
+	}
+	if err != nil {
+		res.Status = HealthStatusDown
+		res.Error = err.Error()
+	}
+	c.last = res
+
+	// export result
+	r := entry.healthRegistry
+	r.lock.Lock()
+	status, latency := r.status, r.latency
+	r.lock.Unlock()
+
+	passed := 0.0
+	if err == nil {
+		passed = 1
+	}
+	status.WithLabelValues(entry.entryName, c.name, strconv.FormatBool(c.critical)).Set(passed)
+	latency.WithLabelValues(entry.entryName, c.name).Set(elapsed.Seconds())
+
+	copied := *res
+	return &copied
+}
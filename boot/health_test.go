@@ -0,0 +1,212 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGinEntry_RegisterHealthCheck(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-health-register"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	noop := func(ctx context.Context) error { return nil }
+
+	assert.Nil(t, entry.RegisterHealthCheck("db", noop,
+		WithHealthCheckNonCritical(),
+		WithHealthCheckTimeout(time.Second),
+		WithHealthCheckCacheInterval(time.Minute),
+		WithHealthCheckStartupOnly()))
+	assert.NotNil(t, entry.RegisterHealthCheck("db", noop))
+	assert.NotNil(t, entry.RegisterHealthCheck("", noop))
+	assert.NotNil(t, entry.RegisterHealthCheck("nil", nil))
+
+	check := entry.healthRegistry.checks[0]
+	assert.False(t, check.critical)
+	assert.True(t, check.startupOnly)
+	assert.Equal(t, time.Second, check.timeout)
+	assert.Equal(t, time.Minute, check.cacheInterval)
+
+	// defaults
+	assert.Nil(t, entry.RegisterHealthCheck("cache", noop, WithHealthCheckTimeout(-1), WithHealthCheckCacheInterval(-1)))
+	check = entry.healthRegistry.checks[1]
+	assert.True(t, check.critical)
+	assert.Equal(t, defaultHealthCheckTimeout, check.timeout)
+	assert.Zero(t, check.cacheInterval)
+
+	assert.Equal(t, "/rk/v1/gin/health", entry.HealthPath())
+}
+
+func TestGinEntry_CheckHealth(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-health-check"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	// no checks
+	assert.Equal(t, HealthStatusUp, entry.CheckHealth(context.TODO()).Status)
+
+	var dbErr atomic.Value
+	dbErr.Store("")
+	assert.Nil(t, entry.RegisterHealthCheck("db", func(ctx context.Context) error {
+		if msg := dbErr.Load().(string); len(msg) > 0 {
+			return errors.New(msg)
+		}
+		return nil
+	}))
+	assert.Nil(t, entry.RegisterHealthCheck("search", func(ctx context.Context) error {
+		return errors.New("ut-error")
+	}, WithHealthCheckNonCritical()))
+
+	res := entry.CheckHealth(context.TODO())
+	assert.Equal(t, HealthStatusDegraded, res.Status)
+	assert.Equal(t, "db", res.Components[0].Name)
+	assert.Equal(t, HealthStatusUp, res.Components[0].Status)
+	assert.Equal(t, HealthStatusDown, res.Components[1].Status)
+	assert.Equal(t, "ut-error", res.Components[1].Error)
+	assert.False(t, res.Components[1].Critical)
+
+	dbErr.Store("connection refused")
+	res = entry.CheckHealth(context.TODO())
+	assert.Equal(t, HealthStatusDown, res.Status)
+	assert.Equal(t, "connection refused", res.Components[0].Error)
+	assert.Equal(t, []string{"db"}, entry.failedCriticalChecks(context.TODO()))
+
+	// results are exported
+	status := entry.healthRegistry.status
+	assert.Equal(t, float64(0), testutil.ToFloat64(status.WithLabelValues("ut-health-check", "db", "true")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(status.WithLabelValues("ut-health-check", "search", "false")))
+	dbErr.Store("")
+	entry.CheckHealth(context.TODO())
+	assert.Equal(t, float64(1), testutil.ToFloat64(status.WithLabelValues("ut-health-check", "db", "true")))
+}
+
+func TestGinEntry_FailedCriticalChecks(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-health-critical"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	var calls int32
+	assert.Nil(t, entry.RegisterHealthCheck("db", func(ctx context.Context) error {
+		return errors.New("ut-error")
+	}))
+	assert.Nil(t, entry.RegisterHealthCheck("search", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}, WithHealthCheckNonCritical()))
+
+	// non-critical checks are not run by readiness
+	assert.Equal(t, []string{"db"}, entry.failedCriticalChecks(context.TODO()))
+	assert.Zero(t, atomic.LoadInt32(&calls))
+
+	entry.CheckHealth(context.TODO())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHealthCheck_Check(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-health-cache"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	var calls int32
+	counted := func(err error) HealthCheck {
+		return func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return err
+		}
+	}
+
+	// cached within interval
+	check := &healthCheck{name: "ut-cache", fn: counted(nil), timeout: time.Second, cacheInterval: time.Minute}
+	assert.False(t, check.check(context.TODO(), entry).Cached)
+	assert.True(t, check.check(context.TODO(), entry).Cached)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// startup-only check stops running once passed
+	atomic.StoreInt32(&calls, 0)
+	check = &healthCheck{name: "ut-startup", fn: counted(errors.New("ut-error")), timeout: time.Second, startupOnly: true}
+	assert.Equal(t, HealthStatusDown, check.check(context.TODO(), entry).Status)
+	check.fn = counted(nil)
+	assert.Equal(t, HealthStatusUp, check.check(context.TODO(), entry).Status)
+	res := check.check(context.TODO(), entry)
+	assert.Equal(t, HealthStatusUp, res.Status)
+	assert.True(t, res.Cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// timeout
+	check = &healthCheck{name: "ut-timeout", timeout: 10 * time.Millisecond, fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}}
+	res = check.check(context.TODO(), entry)
+	assert.Equal(t, HealthStatusDown, res.Status)
+	assert.Contains(t, res.Error, "timeout after 10ms")
+
+	// panic
+	check = &healthCheck{name: "ut-panic", timeout: time.Second, fn: func(ctx context.Context) error {
+		panic("ut-panic")
+	}}
+	assert.Contains(t, check.check(context.TODO(), entry).Error, "ut-panic")
+}
+
+func TestGinEntry_HealthEndpoint(t *testing.T) {
+	entry := RegisterGinEntry(
+		WithName("ut-health-endpoint"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithCommonServiceEntry(rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+			Enabled: true,
+		})))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	var healthy atomic.Value
+	healthy.Store(true)
+	assert.Nil(t, entry.RegisterHealthCheck("db", func(ctx context.Context) error {
+		if !healthy.Load().(bool) {
+			return errors.New("ut-error")
+		}
+		return nil
+	}))
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// healthy
+	w := healthRequest(entry, entry.HealthPath())
+	assert.Equal(t, http.StatusOK, w.Code)
+	res := &HealthResp{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), res))
+	assert.Equal(t, HealthStatusUp, res.Status)
+	assert.Len(t, res.Components, 1)
+	assert.Equal(t, http.StatusOK, healthRequest(entry, entry.CommonServiceEntry.ReadyPath).Code)
+
+	// critical check failed
+	healthy.Store(false)
+	w = healthRequest(entry, entry.HealthPath())
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"ut-error"`)
+
+	w = healthRequest(entry, entry.CommonServiceEntry.ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Critical health checks failed: db")
+}
+
+func healthRequest(entry *GinEntry, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	entry.Router.ServeHTTP(w, req)
+
+	return w
+}
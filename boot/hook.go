@@ -0,0 +1,157 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"fmt"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// HookBeforeBootstrap hooks are called before listeners are bound, error aborts bootstrap
+	HookBeforeBootstrap = "beforeBootstrap"
+	// HookAfterListen hooks are called after listeners are bound and built-in routes are registered
+	HookAfterListen = "afterListen"
+	// HookBeforeShutdown hooks are called after readiness flipped and before server is shut down
+	HookBeforeShutdown = "beforeShutdown"
+	// HookAfterShutdown hooks are called after server and management server are shut down
+	HookAfterShutdown = "afterShutdown"
+
+	// defaultHookTimeout timeout of hook if missing
+	defaultHookTimeout = 5 * time.Second
+)
+
+// LifecycleHook is called in lifecycle phases of entry.
+//
+// ctx is derived from context passed to Bootstrap or Interrupt, and is cancelled once timeout of hook exceeded.
+type LifecycleHook func(ctx context.Context) error
+
+// lifecycleHook is hook registered with name and timeout.
+type lifecycleHook struct {
+	name    string
+	timeout time.Duration
+	fn      LifecycleHook
+}
+
+// OnBeforeBootstrap registers hook called before listeners are bound, like warming caches.
+//
+// Bootstrap would be aborted if hook returns error, hooks registered later are skipped.
+// Default timeout, 5 seconds, would be used if timeout is not positive.
+func (entry *GinEntry) OnBeforeBootstrap(name string, timeout time.Duration, hook LifecycleHook) {
+	entry.addHook(HookBeforeBootstrap, name, timeout, hook)
+}
+
+// OnAfterListen registers hook called after listeners are bound and built-in routes like common service are
+// registered, and before server starts serving, routes could be registered into Router in hook.
+//
+// Error of hook would be recorded in bootstrap event, bootstrap is not aborted.
+// Default timeout, 5 seconds, would be used if timeout is not positive.
+func (entry *GinEntry) OnAfterListen(name string, timeout time.Duration, hook LifecycleHook) {
+	entry.addHook(HookAfterListen, name, timeout, hook)
+}
+
+// OnBeforeShutdown registers hook called after readiness flipped and pre-stop delay passed, and before server
+// is shut down, like flushing queues.
+//
+// Error of hook would be recorded in interrupt event, shutdown is not aborted.
+// Default timeout, 5 seconds, would be used if timeout is not positive.
+func (entry *GinEntry) OnBeforeShutdown(name string, timeout time.Duration, hook LifecycleHook) {
+	entry.addHook(HookBeforeShutdown, name, timeout, hook)
+}
+
+// OnAfterShutdown registers hook called after server and management server are shut down, like closing clients.
+//
+// Error of hook would be recorded in interrupt event.
+// Default timeout, 5 seconds, would be used if timeout is not positive.
+func (entry *GinEntry) OnAfterShutdown(name string, timeout time.Duration, hook LifecycleHook) {
+	entry.addHook(HookAfterShutdown, name, timeout, hook)
+}
+
+// addHook appends hook of phase, hooks are called in order of registration.
+func (entry *GinEntry) addHook(phase, name string, timeout time.Duration, hook LifecycleHook) {
+	if hook == nil {
+		return
+	}
+
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+
+	entry.hookLock.Lock()
+	defer entry.hookLock.Unlock()
+
+	if entry.hooks == nil {
+		entry.hooks = make(map[string][]*lifecycleHook)
+	}
+
+	entry.hooks[phase] = append(entry.hooks[phase], &lifecycleHook{
+		name:    name,
+		timeout: timeout,
+		fn:      hook,
+	})
+}
+
+// runHooks calls hooks of phase in order and records errors in event.
+//
+// If failFast is true, hooks after the failed one are skipped. The first error would be returned.
+func (entry *GinEntry) runHooks(ctx context.Context, phase string, event rkquery.Event, logger *zap.Logger, failFast bool) error {
+	entry.hookLock.Lock()
+	hooks := append([]*lifecycleHook{}, entry.hooks[phase]...)
+	entry.hookLock.Unlock()
+
+	var res error
+	for _, hook := range hooks {
+		if err := callWithTimeout(ctx, hook.timeout, hook.fn); err != nil {
+			err = fmt.Errorf("%s hook %q of gin entry %q: %v", phase, hook.name, entry.entryName, err)
+			event.AddErr(err)
+			logger.Warn("Error occurs while calling lifecycle hook.",
+				zap.String("phase", phase), zap.String("hook", hook.name), zap.Error(err))
+
+			if res == nil {
+				res = err
+			}
+
+			if failFast {
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// callWithTimeout calls fn with timeout and converts panic into error, fn which ignores ctx keeps running in
+// background after timeout exceeded.
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- callRecovered(ctx, fn)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timeout after %s: %v", timeout, ctx.Err())
+	}
+}
+
+// callRecovered calls fn and converts panic into error.
+func callRecovered(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return fn(ctx)
+}
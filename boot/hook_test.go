@@ -0,0 +1,120 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGinEntry_LifecycleHooks(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-hook"), WithHost("127.0.0.1"), WithPort(0))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	calls := make([]string, 0)
+	record := func(name string) LifecycleHook {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	entry.OnBeforeBootstrap("warm-up", 0, func(ctx context.Context) error {
+		calls = append(calls, "warm-up")
+		// listener is not bound yet
+		assert.Nil(t, entry.ListenAddr())
+		return nil
+	})
+	entry.OnAfterListen("routes", time.Second, func(ctx context.Context) error {
+		calls = append(calls, "routes")
+		entry.Router.GET("/ut-hook", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		return nil
+	})
+	entry.OnAfterListen("failed", time.Second, func(ctx context.Context) error {
+		return errors.New("ut-error")
+	})
+	entry.OnAfterListen("nil", time.Second, nil)
+	entry.OnBeforeShutdown("flush", time.Second, func(ctx context.Context) error {
+		calls = append(calls, "flush")
+		// server is still running
+		resp, err := http.Get("http://" + entry.ListenAddr().String() + "/ut-hook")
+		if assert.Nil(t, err) {
+			resp.Body.Close()
+		}
+		return nil
+	})
+	entry.OnAfterShutdown("close", time.Second, record("close"))
+
+	// error of after-listen hook does not abort bootstrap
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	assert.Equal(t, []string{"warm-up", "routes"}, calls)
+
+	resp, err := http.Get("http://" + entry.ListenAddr().String() + "/ut-hook")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	entry.Interrupt(context.TODO())
+	assert.Equal(t, []string{"warm-up", "routes", "flush", "close"}, calls)
+}
+
+func TestGinEntry_BeforeBootstrapHookFailed(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-hook-failed"), WithHost("127.0.0.1"), WithPort(0))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	skipped := true
+	entry.OnBeforeBootstrap("failed", time.Second, func(ctx context.Context) error {
+		return errors.New("ut-error")
+	})
+	entry.OnBeforeBootstrap("skipped", time.Second, func(ctx context.Context) error {
+		skipped = false
+		return nil
+	})
+
+	err := entry.BootstrapE(context.TODO())
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), `beforeBootstrap hook "failed" of gin entry "ut-hook-failed": ut-error`)
+	}
+	assert.True(t, skipped)
+	// listener is not bound
+	assert.Nil(t, entry.ListenAddr())
+}
+
+func TestCallWithTimeout(t *testing.T) {
+	// timeout
+	err := callWithTimeout(context.TODO(), 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "timeout after 10ms")
+	}
+
+	// panic
+	err = callWithTimeout(context.TODO(), time.Second, func(ctx context.Context) error {
+		panic("ut-panic")
+	})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "panic: ut-panic")
+	}
+}
+
+func TestLifecycleHook_DefaultTimeout(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-hook-timeout"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	entry.OnAfterShutdown("ut", -1, func(ctx context.Context) error { return nil })
+	assert.Equal(t, defaultHookTimeout, entry.hooks[HookAfterShutdown][0].timeout)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net/http"
+	"time"
+)
+
+// BootHttp2 bootstrap config of HTTP/2.
+//
+// If H2c is enabled, HTTP/2 without TLS would be accepted with both prior knowledge and Upgrade: h2c header.
+// Zero values of tuning options means default of golang.org/x/net/http2 would be used.
+type BootHttp2 struct {
+	H2c                  bool   `yaml:"h2c" json:"h2c"`
+	MaxConcurrentStreams uint32 `yaml:"maxConcurrentStreams" json:"maxConcurrentStreams"`
+	MaxReadFrameSize     uint32 `yaml:"maxReadFrameSize" json:"maxReadFrameSize"`
+	IdleTimeoutMs        int    `yaml:"idleTimeoutMs" json:"idleTimeoutMs"`
+}
+
+// ToOptions convert BootHttp2 into GinEntryOption list.
+func (boot *BootHttp2) ToOptions() []GinEntryOption {
+	opts := make([]GinEntryOption, 0)
+
+	if boot.H2c {
+		opts = append(opts, WithH2c())
+	}
+
+	if boot.MaxConcurrentStreams > 0 {
+		opts = append(opts, WithHttp2MaxConcurrentStreams(boot.MaxConcurrentStreams))
+	}
+
+	if boot.MaxReadFrameSize > 0 {
+		opts = append(opts, WithHttp2MaxReadFrameSize(boot.MaxReadFrameSize))
+	}
+
+	if boot.IdleTimeoutMs > 0 {
+		opts = append(opts, WithHttp2IdleTimeout(time.Duration(boot.IdleTimeoutMs)*time.Millisecond))
+	}
+
+	return opts
+}
+
+// IsH2cEnabled Is HTTP/2 without TLS enabled?
+func (entry *GinEntry) IsH2cEnabled() bool {
+	return entry.h2c
+}
+
+// getHttp2Server returns http2.Server which holds tuning options, creates one if missing.
+func (entry *GinEntry) getHttp2Server() *http2.Server {
+	if entry.http2Server == nil {
+		entry.http2Server = &http2.Server{}
+	}
+
+	return entry.http2Server
+}
+
+// wrapH2c wraps handler to accept HTTP/2 without TLS if h2c enabled.
+func (entry *GinEntry) wrapH2c(handler http.Handler) http.Handler {
+	if !entry.IsH2cEnabled() {
+		return handler
+	}
+
+	return h2c.NewHandler(handler, entry.getHttp2Server())
+}
+
+// configureHttp2 applies HTTP/2 tuning options to http.Server.
+//
+// If no option was provided, HTTP/2 would be configured by net/http implicitly while serving TLS.
+func (entry *GinEntry) configureHttp2() error {
+	if entry.http2Server == nil {
+		return nil
+	}
+
+	return http2.ConfigureServer(entry.Server, entry.http2Server)
+}
+
+// WithH2c enable HTTP/2 without TLS.
+func WithH2c() GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.h2c = true
+		entry.getHttp2Server()
+	}
+}
+
+// WithHttp2MaxConcurrentStreams provide max number of concurrent streams per HTTP/2 connection.
+func WithHttp2MaxConcurrentStreams(streams uint32) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.getHttp2Server().MaxConcurrentStreams = streams
+	}
+}
+
+// WithHttp2MaxReadFrameSize provide max size of HTTP/2 frame the server would read.
+func WithHttp2MaxReadFrameSize(size uint32) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.getHttp2Server().MaxReadFrameSize = size
+	}
+}
+
+// WithHttp2IdleTimeout provide duration of idle HTTP/2 connection before closed.
+func WithHttp2IdleTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.getHttp2Server().IdleTimeout = timeout
+	}
+}
@@ -0,0 +1,143 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBootHttp2_ToOptions(t *testing.T) {
+	// default
+	entry := RegisterGinEntry(WithName("ut-http2-default"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.False(t, entry.IsH2cEnabled())
+	assert.Nil(t, entry.http2Server)
+	assert.Nil(t, entry.configureHttp2())
+
+	// with options
+	boot := &BootHttp2{
+		H2c:                  true,
+		MaxConcurrentStreams: 10,
+		MaxReadFrameSize:     1 << 20,
+		IdleTimeoutMs:        1000,
+	}
+	entry = RegisterGinEntry(append(boot.ToOptions(), WithName("ut-http2-options"))...)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.True(t, entry.IsH2cEnabled())
+	assert.Equal(t, uint32(10), entry.http2Server.MaxConcurrentStreams)
+	assert.Equal(t, uint32(1<<20), entry.http2Server.MaxReadFrameSize)
+	assert.Equal(t, time.Second, entry.http2Server.IdleTimeout)
+	assert.Contains(t, entry.String(), `"h2c":true`)
+}
+
+func TestRegisterGinEntryYAML_WithHttp2(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-http2-yaml
+    port: 1949
+    enabled: true
+    http2:
+      h2c: true
+      maxConcurrentStreams: 20
+`
+	entries := RegisterGinEntryYAML([]byte(bootStr))
+	entry := entries["ut-http2-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.True(t, entry.IsH2cEnabled())
+	assert.Equal(t, uint32(20), entry.http2Server.MaxConcurrentStreams)
+}
+
+func TestGinEntry_H2c(t *testing.T) {
+	entry := RegisterGinEntry(
+		WithName("ut-h2c"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithH2c())
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.Request.Proto)
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	url := "http://" + entry.ListenAddr().String() + "/ut"
+
+	// prior knowledge
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		},
+	}
+	assert.Equal(t, "HTTP/2.0", http2Response(t, client, url))
+
+	// HTTP/1.1 still works
+	assert.Equal(t, "HTTP/1.1", http2Response(t, &http.Client{}, url))
+}
+
+func TestGinEntry_Http2Tls(t *testing.T) {
+	ca, caKey := generateCA()
+	cert := generateLeaf(ca, caKey, "localhost", nil)
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name: "ut-http2-tls",
+			},
+		},
+	})[0]
+	certEntry.Certificate = &cert
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	entry := RegisterGinEntry(
+		WithName("ut-http2-tls"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithCertEntry(certEntry),
+		WithHttp2MaxConcurrentStreams(10))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.Request.Proto)
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	url := "https://" + entry.ListenAddr().String() + "/ut"
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+	}
+	assert.Equal(t, "HTTP/2.0", http2Response(t, client, url))
+}
+
+func http2Response(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	assert.Nil(t, err)
+	if resp == nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	bytes, _ := io.ReadAll(resp.Body)
+	return string(bytes)
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// redacted replaces values of secrets in introspection response
+	redacted = "******"
+	// defaultCommonServicePrefix prefix of introspection path if common service is disabled
+	defaultCommonServicePrefix = "/rk/v1"
+)
+
+// secretKeyParts are parts of keys of boot config whose values would be redacted, like accessToken, api_keys or
+// dbPassword. Keys are matched in lower case without separators, over-redaction is preferred to leaking secrets
+// of custom middlewares.
+var secretKeyParts = []string{
+	"key",
+	"secret",
+	"token",
+	"pass",
+	"auth",
+	"credential",
+	"dsn",
+	"basic",
+}
+
+// BootIntrospection bootstrap config of introspection endpoint.
+//
+// Resolved config, middleware chain and routes of gin entries would be served at <commonService.pathPrefix>/gin/entries
+// with secrets redacted. The endpoint goes through auth middleware of entry.
+type BootIntrospection struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+}
+
+// IsIntrospectionEnabled Is introspection endpoint enabled?
+func (entry *GinEntry) IsIntrospectionEnabled() bool {
+	return entry.introspection
+}
+
+// IntrospectionPath returns path of introspection endpoint under prefix of common service.
+func (entry *GinEntry) IntrospectionPath() string {
+	prefix := defaultCommonServicePrefix
+	if entry.IsCommonServiceEnabled() {
+		prefix = path.Dir(entry.CommonServiceEntry.ReadyPath)
+	}
+
+	return path.Join(prefix, "gin", "entries")
+}
+
+// WithIntrospection enable introspection endpoint under prefix of common service.
+func WithIntrospection() GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.introspection = true
+	}
+}
+
+// registerIntrospection registers introspection endpoint into router.
+//
+// Middlewares of entry are not installed on management router, so auth middleware is added to the route explicitly.
+func (entry *GinEntry) registerIntrospection(router *gin.Engine) {
+	handlers := make([]gin.HandlerFunc, 0)
+	if router != entry.Router {
+		if auth, ok := entry.namedMiddlewares[MiddlewareAuth]; ok {
+			handlers = append(handlers, auth.handle)
+		}
+	}
+	handlers = append(handlers, entry.introspect)
+
+	router.GET(entry.IntrospectionPath(), handlers...)
+}
+
+// introspect responds with introspection of all gin entries.
+func (entry *GinEntry) introspect(ctx *gin.Context) {
+	entries := rkentry.GlobalAppCtx.ListEntriesByType(GinEntryType)
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*IntrospectionResp, 0, len(names))
+	for _, name := range names {
+		if ginEntry, ok := entries[name].(*GinEntry); ok {
+			res = append(res, ginEntry.Introspect())
+		}
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"entries": res,
+	})
+}
+
+// IntrospectionResp is resolved config, middleware chain and routes of GinEntry.
+type IntrospectionResp struct {
+	Name             string                `json:"name" yaml:"name"`
+	Description      string                `json:"description" yaml:"description"`
+	ListenAddr       string                `json:"listenAddr,omitempty" yaml:"listenAddr,omitempty"`
+	Config           interface{}           `json:"config" yaml:"config"`
+	MiddlewareChain  []string              `json:"middlewareChain" yaml:"middlewareChain"`
+	RouteGroups      []*IntrospectionGroup `json:"routeGroups" yaml:"routeGroups"`
+	Routes           []*IntrospectionRoute `json:"routes" yaml:"routes"`
+	ManagementRoutes []*IntrospectionRoute `json:"managementRoutes,omitempty" yaml:"managementRoutes,omitempty"`
+}
+
+// IntrospectionGroup is route group with middleware chain.
+type IntrospectionGroup struct {
+	Name            string   `json:"name" yaml:"name"`
+	Path            string   `json:"path" yaml:"path"`
+	MiddlewareChain []string `json:"middlewareChain" yaml:"middlewareChain"`
+}
+
+// IntrospectionRoute is route registered on router with name of handler.
+type IntrospectionRoute struct {
+	Method  string `json:"method" yaml:"method"`
+	Path    string `json:"path" yaml:"path"`
+	Handler string `json:"handler" yaml:"handler"`
+}
+
+// Introspect returns resolved config, middleware chain and routes of entry with secrets redacted.
+//
+// Config is the boot config of entry registered with RegisterGinEntryYAML, nil if entry was registered in code.
+func (entry *GinEntry) Introspect() *IntrospectionResp {
+	res := &IntrospectionResp{
+		Name:            entry.entryName,
+		Description:     entry.entryDescription,
+		MiddlewareChain: entry.GetMiddlewareChain(),
+		RouteGroups:     make([]*IntrospectionGroup, 0, len(entry.routeGroups)),
+		Routes:          toIntrospectionRoutes(entry.Router),
+	}
+
+	if addr := entry.ListenAddr(); addr != nil {
+		res.ListenAddr = addr.String()
+	}
+
+	entry.reloadLock.Lock()
+	bootConfig := entry.bootConfig
+	entry.reloadLock.Unlock()
+
+	if bootConfig != nil {
+		config := *bootConfig
+
+		// config of custom middlewares may contain maps with interface{} keys decoded from YAML
+		config.Middleware.Custom = toJsonCustom(config.Middleware.Custom)
+		config.RouteGroups = append([]BootRouteGroup{}, config.RouteGroups...)
+		for i := range config.RouteGroups {
+			config.RouteGroups[i].Middleware.Custom = toJsonCustom(config.RouteGroups[i].Middleware.Custom)
+		}
+
+		res.Config = redactConfig(&config)
+	}
+
+	for _, group := range entry.routeGroups {
+		res.RouteGroups = append(res.RouteGroups, &IntrospectionGroup{
+			Name:            group.name,
+			Path:            group.path,
+			MiddlewareChain: append([]string{}, group.chain...),
+		})
+	}
+
+	if entry.IsManagementEnabled() {
+		res.ManagementRoutes = toIntrospectionRoutes(entry.ManagementRouter)
+	}
+
+	return res
+}
+
+// toIntrospectionRoutes lists routes of router in order of path and method.
+func toIntrospectionRoutes(router *gin.Engine) []*IntrospectionRoute {
+	res := make([]*IntrospectionRoute, 0)
+	if router == nil {
+		return res
+	}
+
+	for _, route := range router.Routes() {
+		res = append(res, &IntrospectionRoute{
+			Method:  route.Method,
+			Path:    route.Path,
+			Handler: route.Handler,
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Method < res[j].Method
+	})
+
+	return res
+}
+
+// toJsonCustom copies custom middlewares with config which could be marshalled into JSON.
+func toJsonCustom(custom []BootCustomMiddleware) []BootCustomMiddleware {
+	res := make([]BootCustomMiddleware, 0, len(custom))
+	for i := range custom {
+		mid := custom[i]
+		mid.Config = toStringKeyMap(mid.Config)
+		res = append(res, mid)
+	}
+
+	return res
+}
+
+// redactConfig converts config into JSON compatible value with values of secret keys redacted.
+func redactConfig(config interface{}) interface{} {
+	bytes, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+
+	var res interface{}
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return nil
+	}
+
+	return redactValue(res, false)
+}
+
+// redactValue redacts non-empty values recursively if secret is true or key of value is a secret key.
+func redactValue(v interface{}, secret bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = redactValue(val[k], secret || isSecretKey(k))
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = redactValue(val[i], secret)
+		}
+		return val
+	case string:
+		if secret && len(val) > 0 {
+			return redacted
+		}
+	}
+
+	return v
+}
+
+// isSecretKey checks whether normalized key contains any of secretKeyParts.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+	for i := range secretKeyParts {
+		if strings.Contains(key, secretKeyParts[i]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,206 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/auth"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGinEntry_IntrospectionPath(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-introspection-path"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.False(t, entry.IsIntrospectionEnabled())
+	assert.Equal(t, "/rk/v1/gin/entries", entry.IntrospectionPath())
+
+	entry = RegisterGinEntry(
+		WithName("ut-introspection-path-prefix"),
+		WithIntrospection(),
+		WithCommonServiceEntry(rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+			Enabled:    true,
+			PathPrefix: "/ut/",
+		})))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.True(t, entry.IsIntrospectionEnabled())
+	assert.Equal(t, "/ut/gin/entries", entry.IntrospectionPath())
+}
+
+func TestRegisterGinEntryYAML_WithIntrospection(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-introspection
+    port: 0
+    enabled: true
+    introspection:
+      enabled: true
+    server:
+      host: 127.0.0.1
+    middleware:
+      auth:
+        enabled: true
+        basic: ["user:pass"]
+        ignore: ["/ut-public"]
+      jwt:
+        symmetric:
+          algorithm: HS256
+          token: my-token
+      custom:
+        - name: ut-introspection
+          config:
+            nested:
+              password: my-password
+    routeGroups:
+      - name: v1
+        path: /v1
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-introspection"].(*GinEntry)
+	entry.Router.GET("/ut-public", func(ctx *gin.Context) {})
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// auth middleware is honoured
+	assert.Equal(t, http.StatusUnauthorized, introspectionRequest(entry.Router, "/rk/v1/gin/entries", false).Code)
+
+	w := introspectionRequest(entry.Router, "/rk/v1/gin/entries", true)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	resp := struct {
+		Entries []*IntrospectionResp `json:"entries"`
+	}{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	var res *IntrospectionResp
+	for i := range resp.Entries {
+		if resp.Entries[i].Name == "ut-introspection" {
+			res = resp.Entries[i]
+		}
+	}
+	assert.NotNil(t, res)
+	assert.Equal(t, entry.ListenAddr().String(), res.ListenAddr)
+	assert.Equal(t, entry.GetMiddlewareChain(), res.MiddlewareChain)
+	assert.Len(t, res.RouteGroups, 1)
+	assert.Equal(t, "/v1", res.RouteGroups[0].Path)
+
+	// routes with handler names
+	paths := make(map[string]string)
+	for _, route := range res.Routes {
+		paths[route.Method+" "+route.Path] = route.Handler
+	}
+	assert.Contains(t, paths, "GET /ut-public")
+	assert.Contains(t, paths["GET /rk/v1/gin/entries"], "introspect")
+
+	// secrets are redacted
+	bytes, _ := json.Marshal(res.Config)
+	assert.Contains(t, string(bytes), `"algorithm":"HS256"`)
+	assert.NotContains(t, string(bytes), "user:pass")
+	assert.NotContains(t, string(bytes), "my-token")
+	assert.NotContains(t, string(bytes), "my-password")
+	assert.Contains(t, string(bytes), redacted)
+}
+
+func TestGinEntry_IntrospectionOnManagement(t *testing.T) {
+	entry := RegisterGinEntry(
+		WithName("ut-introspection-mgmt"),
+		WithManagementPort(8096),
+		WithIntrospection())
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	entry.AddNamedMiddleware(MiddlewareAuth, rkginauth.Middleware(rkmidauth.WithBasicAuth("", "user:pass")))
+	entry.registerIntrospection(entry.ManagementRouter)
+
+	// management router does not have middlewares of entry, auth middleware is added to route
+	assert.Equal(t, http.StatusUnauthorized, introspectionRequest(entry.ManagementRouter, "/rk/v1/gin/entries", false).Code)
+	assert.Equal(t, http.StatusOK, introspectionRequest(entry.ManagementRouter, "/rk/v1/gin/entries", true).Code)
+
+	res := entry.Introspect()
+	assert.Nil(t, res.Config)
+	assert.Len(t, res.ManagementRoutes, 1)
+	assert.Equal(t, "/rk/v1/gin/entries", res.ManagementRoutes[0].Path)
+}
+
+func TestRedactConfig(t *testing.T) {
+	res := redactConfig(map[string]interface{}{
+		"Token": "secret",
+		"path":  "/path",
+		"basic": []string{"user:pass", ""},
+		"nested": map[string]interface{}{
+			"apiKey": map[string]interface{}{"name": "value"},
+		},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"Token": redacted,
+		"path":  "/path",
+		"basic": []interface{}{redacted, ""},
+		"nested": map[string]interface{}{
+			"apiKey": map[string]interface{}{"name": redacted},
+		},
+	}, res)
+
+	// nested and camelCase keys of custom middlewares
+	res = redactConfig(map[string]interface{}{
+		"custom": []interface{}{
+			map[string]interface{}{
+				"name": "ut-custom",
+				"config": map[string]interface{}{
+					"secretKey":   "value",
+					"accessToken": "value",
+					"db_password": "value",
+					"DSN":         "value",
+					"upstream": map[string]interface{}{
+						"credentials": map[string]interface{}{"user": "value"},
+						"api-keys":    []interface{}{"value"},
+						"url":         "http://localhost",
+					},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"custom": []interface{}{
+			map[string]interface{}{
+				"name": "ut-custom",
+				"config": map[string]interface{}{
+					"secretKey":   redacted,
+					"accessToken": redacted,
+					"db_password": redacted,
+					"DSN":         redacted,
+					"upstream": map[string]interface{}{
+						"credentials": map[string]interface{}{"user": redacted},
+						"api-keys":    []interface{}{redacted},
+						"url":         "http://localhost",
+					},
+				},
+			},
+		},
+	}, res)
+
+	// invalid JSON
+	assert.Nil(t, redactConfig(map[string]interface{}{"ch": make(chan int)}))
+}
+
+func introspectionRequest(router *gin.Engine, path string, withAuth bool) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if withAuth {
+		req.SetBasicAuth("user", "pass")
+	}
+	router.ServeHTTP(w, req)
+
+	return w
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ListenerTypeTcp listen on host and port of GinEntry
+	ListenerTypeTcp = "tcp"
+	// ListenerTypeUnix listen on unix domain socket
+	ListenerTypeUnix = "unix"
+	// ListenerTypeFd listen on file descriptor inherited from parent process
+	ListenerTypeFd = "fd"
+	// ListenerTypeSystemd listen on socket passed by systemd socket activation
+	ListenerTypeSystemd = "systemd"
+
+	// systemdListenFdsStart first file descriptor passed by systemd, see sd_listen_fds(3)
+	systemdListenFdsStart = 3
+)
+
+// BootListener bootstrap config of listener.
+//
+// Path and Mode are used by unix listener, Mode is octal file mode of socket like 0660.
+// Fd is file descriptor number for fd listener and index of passed sockets for systemd listener.
+type BootListener struct {
+	Type string `yaml:"type" json:"type"`
+	Path string `yaml:"path" json:"path"`
+	Mode string `yaml:"mode" json:"mode"`
+	Fd   int    `yaml:"fd" json:"fd"`
+}
+
+// ToOptions convert BootListener into GinEntryOption list.
+func (boot *BootListener) ToOptions() ([]GinEntryOption, error) {
+	switch strings.ToLower(boot.Type) {
+	case "", ListenerTypeTcp:
+		return []GinEntryOption{}, nil
+	case ListenerTypeUnix:
+		if len(boot.Path) < 1 {
+			return nil, errors.New("path of unix listener is empty")
+		}
+
+		var mode uint64
+		if len(boot.Mode) > 0 {
+			var err error
+			if mode, err = strconv.ParseUint(boot.Mode, 8, 32); err != nil {
+				return nil, fmt.Errorf("invalid mode %q of unix listener, expect octal like 0660", boot.Mode)
+			}
+		}
+
+		return []GinEntryOption{WithUnixSocket(boot.Path, os.FileMode(mode))}, nil
+	case ListenerTypeFd:
+		if boot.Fd < 0 {
+			return nil, fmt.Errorf("invalid fd %d of fd listener", boot.Fd)
+		}
+		return []GinEntryOption{WithInheritedFd(boot.Fd)}, nil
+	case ListenerTypeSystemd:
+		if boot.Fd < 0 {
+			return nil, fmt.Errorf("invalid fd index %d of systemd listener", boot.Fd)
+		}
+		return []GinEntryOption{WithSystemdSocket(boot.Fd)}, nil
+	}
+
+	return nil, fmt.Errorf("invalid listener type %q, expect one of [%s, %s, %s, %s]",
+		boot.Type, ListenerTypeTcp, ListenerTypeUnix, ListenerTypeFd, ListenerTypeSystemd)
+}
+
+// ListenAddr returns address which gin server bound to, nil will be returned before bootstrap.
+//
+// If port 0 was provided, the returned address contains the ephemeral port chosen by system.
+func (entry *GinEntry) ListenAddr() net.Addr {
+	if entry.listener == nil {
+		return nil
+	}
+
+	return entry.listener.Addr()
+}
+
+// ListenPort returns port which gin server bound to, configured port will be returned before bootstrap.
+func (entry *GinEntry) ListenPort() uint64 {
+	return portOf(entry.ListenAddr(), entry.Port)
+}
+
+// listen binds listener of gin server synchronously and validates TLS config.
+//
+// Nil listener would be returned if entry joined virtual host whose listener was bound by another entry.
+func (entry *GinEntry) listen() (net.Listener, error) {
+	if entry.IsTlsEnabled() {
+		entry.Server.TLSConfig = entry.newTlsConfig()
+		if err := validateTlsConfig(entry.Server.TLSConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	// entries with hosts share listener on the same address
+	if len(entry.hosts) > 0 {
+		return entry.joinVirtualHost()
+	}
+
+	return entry.bind()
+}
+
+// bind creates listener with PROXY protocol support and configures HTTP/2 of server.
+func (entry *GinEntry) bind() (net.Listener, error) {
+	// TLSConfig is replaced before binding, so HTTP/2 must be configured after it
+	if err := entry.configureHttp2(); err != nil {
+		return nil, err
+	}
+
+	ln, err := entry.rawListener()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep raw listener, since it would be passed to child process while restarting gracefully
+	entry.listener = ln
+
+	// PROXY protocol header is read beneath TLS
+	if entry.IsProxyProtocolEnabled() {
+		ln = &proxyProtocolListener{
+			Listener:      ln,
+			trusted:       entry.proxyTrustedCidrs,
+			headerTimeout: entry.proxyHeaderTimeout,
+		}
+	}
+
+	return ln, nil
+}
+
+// rawListener creates listener based on listener type.
+func (entry *GinEntry) rawListener() (net.Listener, error) {
+	if entry.customListener != nil {
+		return entry.customListener, nil
+	}
+
+	// listener passed by parent process while restarting gracefully
+	if ln, ok, err := inherited.listener(entry.listenerName()); ok {
+		return ln, err
+	}
+
+	switch entry.listenerType {
+	case ListenerTypeUnix:
+		return listenUnix(entry.socketPath, entry.socketMode)
+	case ListenerTypeFd:
+		return listenFd(entry.listenerFd)
+	case ListenerTypeSystemd:
+		fd, err := systemdFd(entry.listenerFd)
+		if err != nil {
+			return nil, err
+		}
+		return listenFd(fd)
+	}
+
+	return net.Listen("tcp", entry.Server.Addr)
+}
+
+// serve blocks and serves requests on listener until server closed.
+func (entry *GinEntry) serve(ln net.Listener) error {
+	// If TLS was enabled, certificate was configured in listen() and served with ServeTLS()
+	if entry.IsTlsEnabled() {
+		return entry.Server.ServeTLS(ln, "", "")
+	}
+
+	return entry.Server.Serve(ln)
+}
+
+// validateTlsConfig checks whether server certificate and client CA are available.
+func validateTlsConfig(conf *tls.Config) error {
+	if conf.GetCertificate != nil {
+		if _, err := conf.GetCertificate(nil); err != nil {
+			return err
+		}
+	} else if len(conf.Certificates) < 1 {
+		return errors.New("no server certificate available")
+	}
+
+	if conf.ClientAuth >= tls.VerifyClientCertIfGiven && conf.ClientCAs == nil {
+		return errors.New("client certificate verification requires rootCA of CertEntry")
+	}
+
+	return nil
+}
+
+// listenUnix listens on unix domain socket, stale socket file would be removed first.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+
+	return ln, nil
+}
+
+// listenFd creates listener from inherited file descriptor.
+func listenFd(fd int) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), "listener-fd-"+strconv.Itoa(fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid listener fd %d", fd)
+	}
+	// net.FileListener duplicates file descriptor
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on fd %d: %v", fd, err)
+	}
+
+	return ln, nil
+}
+
+// systemdFd returns file descriptor of socket at index passed by systemd socket activation.
+func systemdFd(index int) (int, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0, errors.New("no sockets passed by systemd, LISTEN_PID does not match")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return 0, errors.New("no sockets passed by systemd, LISTEN_FDS is invalid")
+	}
+
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("systemd socket index %d out of range, %d sockets passed", index, count)
+	}
+
+	return systemdListenFdsStart + index, nil
+}
+
+// portOf returns port of TCP address, fallback will be returned if address is not TCP.
+func portOf(addr net.Addr, fallback uint64) uint64 {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return uint64(tcpAddr.Port)
+	}
+
+	return fallback
+}
+
+// WithListener provide listener which gin server would serve on, host and port would be ignored.
+//
+// Listener would be closed while interrupting GinEntry.
+func WithListener(ln net.Listener) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.customListener = ln
+	}
+}
+
+// WithUnixSocket provide unix domain socket path and file mode which gin server would listen on.
+func WithUnixSocket(path string, mode os.FileMode) GinEntryOption {
+	return func(entry *GinEntry) {
+		if len(path) > 0 {
+			entry.listenerType = ListenerTypeUnix
+			entry.socketPath = path
+			entry.socketMode = mode
+		}
+	}
+}
+
+// WithInheritedFd provide file descriptor of listening socket inherited from parent process.
+func WithInheritedFd(fd int) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.listenerType = ListenerTypeFd
+		entry.listenerFd = fd
+	}
+}
+
+// WithSystemdSocket provide index of socket passed by systemd socket activation.
+func WithSystemdSocket(index int) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.listenerType = ListenerTypeSystemd
+		entry.listenerFd = index
+	}
+}
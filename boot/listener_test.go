@@ -0,0 +1,254 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestPortOf(t *testing.T) {
+	assert.Equal(t, uint64(1949), portOf(nil, 1949))
+	assert.Equal(t, uint64(8080), portOf(&net.TCPAddr{Port: 8080}, 1949))
+	assert.Equal(t, uint64(1949), portOf(&net.UnixAddr{Name: "ut.sock"}, 1949))
+}
+
+func TestValidateTlsConfig(t *testing.T) {
+	// without certificate
+	assert.NotNil(t, validateTlsConfig(&tls.Config{}))
+
+	// with certificate
+	ca, caKey := generateCA()
+	cert := generateLeaf(ca, caKey, "localhost", nil)
+	assert.Nil(t, validateTlsConfig(&tls.Config{Certificates: []tls.Certificate{cert}}))
+
+	// verify client certificate without client CA
+	assert.NotNil(t, validateTlsConfig(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}))
+}
+
+func TestGinEntry_BootstrapE_EphemeralPort(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-ephemeral"), WithHost("127.0.0.1"), WithPort(0))
+	assert.Nil(t, entry.ListenAddr())
+	assert.Zero(t, entry.ListenPort())
+
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// listener is bound once BootstrapE returns
+	assert.NotNil(t, entry.ListenAddr())
+	assert.NotZero(t, entry.ListenPort())
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/ut", entry.ListenAddr().String()))
+	assert.Nil(t, err)
+	bytes, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(bytes))
+}
+
+func TestGinEntry_BootstrapE_Error(t *testing.T) {
+	// occupy a port
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+
+	entry := RegisterGinEntry(
+		WithName("ut-bind-error"),
+		WithHost("127.0.0.1"),
+		WithPort(portOf(ln.Addr(), 0)))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.ListenAddr())
+
+	// management listener fails, main listener should be released
+	entry = RegisterGinEntry(
+		WithName("ut-bind-mgmt-error"),
+		WithHost("127.0.0.1"),
+		WithManagementHost("127.0.0.1"),
+		WithManagementPort(portOf(ln.Addr(), 0)))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.ListenAddr())
+	assert.Nil(t, entry.ManagementListenAddr())
+
+	// invalid TLS config
+	ca, caKey := generateCA()
+	cert := generateLeaf(ca, caKey, "localhost", nil)
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name: "ut-bind-tls-error",
+			},
+		},
+	})[0]
+	certEntry.Certificate = &cert
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	entry = RegisterGinEntry(
+		WithName("ut-bind-tls-error"),
+		WithHost("127.0.0.1"),
+		WithCertEntry(certEntry),
+		WithClientAuth(tls.RequireAndVerifyClientCert))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.ListenAddr())
+}
+
+func TestBootListener_ToOptions(t *testing.T) {
+	// default
+	opts, err := (&BootListener{}).ToOptions()
+	assert.Nil(t, err)
+	assert.Empty(t, opts)
+
+	// unix
+	opts, err = (&BootListener{Type: "unix", Path: "ut.sock", Mode: "0660"}).ToOptions()
+	assert.Nil(t, err)
+	entry := &GinEntry{}
+	for i := range opts {
+		opts[i](entry)
+	}
+	assert.Equal(t, ListenerTypeUnix, entry.listenerType)
+	assert.Equal(t, "ut.sock", entry.socketPath)
+	assert.Equal(t, os.FileMode(0660), entry.socketMode)
+
+	// systemd
+	opts, err = (&BootListener{Type: "systemd", Fd: 1}).ToOptions()
+	assert.Nil(t, err)
+	entry = &GinEntry{}
+	for i := range opts {
+		opts[i](entry)
+	}
+	assert.Equal(t, ListenerTypeSystemd, entry.listenerType)
+	assert.Equal(t, 1, entry.listenerFd)
+
+	// invalid
+	_, err = (&BootListener{Type: "invalid"}).ToOptions()
+	assert.NotNil(t, err)
+	_, err = (&BootListener{Type: "unix"}).ToOptions()
+	assert.NotNil(t, err)
+	_, err = (&BootListener{Type: "unix", Path: "ut.sock", Mode: "rw"}).ToOptions()
+	assert.NotNil(t, err)
+	_, err = (&BootListener{Type: "fd", Fd: -1}).ToOptions()
+	assert.NotNil(t, err)
+}
+
+func TestSystemdFd(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	_, err := systemdFd(0)
+	assert.NotNil(t, err)
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	fd, err := systemdFd(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, fd)
+
+	_, err = systemdFd(2)
+	assert.NotNil(t, err)
+}
+
+func TestGinEntry_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ut.sock")
+
+	entry := RegisterGinEntry(
+		WithName("ut-unix"),
+		WithUnixSocket(path, 0600))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	assertListenerServes(t, client, "http://unix/ut")
+}
+
+func TestGinEntry_WithListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	entry := RegisterGinEntry(
+		WithName("ut-with-listener"),
+		WithListener(ln))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assert.Equal(t, ln.Addr(), entry.ListenAddr())
+	assertListenerServes(t, http.DefaultClient, fmt.Sprintf("http://%s/ut", ln.Addr().String()))
+}
+
+func TestGinEntry_InheritedFd(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	assert.Nil(t, err)
+	defer f.Close()
+
+	entry := RegisterGinEntry(
+		WithName("ut-inherited-fd"),
+		WithInheritedFd(int(f.Fd())))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assertListenerServes(t, http.DefaultClient, fmt.Sprintf("http://%s/ut", ln.Addr().String()))
+}
+
+func assertListenerServes(t *testing.T, client *http.Client, url string) {
+	resp, err := client.Get(url)
+	assert.Nil(t, err)
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	bytes, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", string(bytes))
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"crypto/tls"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/panic"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/panic"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// BootManagement bootstrap config of management listener.
+//
+// If port was provided, prom, pprof, swagger, docs and common service would be served with a dedicated
+// http.Server instead of the one serving business traffic.
+type BootManagement struct {
+	Port      uint64 `yaml:"port" json:"port"`
+	Host      string `yaml:"host" json:"host"`
+	CertEntry string `yaml:"certEntry" json:"certEntry"`
+}
+
+// IsManagementEnabled Is dedicated management listener enabled?
+func (entry *GinEntry) IsManagementEnabled() bool {
+	return entry.ManagementPort > 0
+}
+
+// IsManagementTlsEnabled Is TLS enabled on management listener?
+func (entry *GinEntry) IsManagementTlsEnabled() bool {
+	return entry.ManagementCertEntry != nil && entry.ManagementCertEntry.Certificate != nil
+}
+
+// initManagement creates router and server of management listener.
+//
+// Only panic middleware is installed, middlewares added with AddMiddleware() would not run on it.
+func (entry *GinEntry) initManagement() {
+	if !entry.IsManagementEnabled() {
+		return
+	}
+
+	entry.ManagementRouter = gin.New()
+	entry.ManagementRouter.Use(rkginpanic.Middleware(
+		rkmidpanic.WithEntryNameAndType(entry.entryName, entry.entryType)))
+
+	entry.ManagementServer = &http.Server{
+		Addr:              net.JoinHostPort(entry.ManagementHost, strconv.FormatUint(entry.ManagementPort, 10)),
+		Handler:           entry.ManagementRouter,
+		ReadTimeout:       entry.readTimeout,
+		ReadHeaderTimeout: entry.readHeaderTimeout,
+		WriteTimeout:      entry.writeTimeout,
+		IdleTimeout:       entry.idleTimeout,
+		MaxHeaderBytes:    entry.maxHeaderBytes,
+	}
+}
+
+// managementRouter returns router which builtin endpoints should be registered into.
+func (entry *GinEntry) managementRouter() *gin.Engine {
+	if entry.IsManagementEnabled() {
+		return entry.ManagementRouter
+	}
+
+	return entry.Router
+}
+
+// managementAddr returns scheme and port of builtin endpoints.
+func (entry *GinEntry) managementAddr() (string, uint64) {
+	if entry.IsManagementEnabled() {
+		port := portOf(entry.ManagementListenAddr(), entry.ManagementPort)
+		if entry.IsManagementTlsEnabled() {
+			return "https", port
+		}
+		return "http", port
+	}
+
+	if entry.IsTlsEnabled() {
+		return "https", entry.ListenPort()
+	}
+	return "http", entry.ListenPort()
+}
+
+// ManagementListenAddr returns address which management server bound to, nil will be returned
+// if management listener is disabled or before bootstrap.
+func (entry *GinEntry) ManagementListenAddr() net.Addr {
+	if entry.managementListener == nil {
+		return nil
+	}
+
+	return entry.managementListener.Addr()
+}
+
+// listenManagement binds listener of management server synchronously.
+func (entry *GinEntry) listenManagement() (net.Listener, error) {
+	if entry.IsManagementTlsEnabled() {
+		// served by certReloader, so it could be rotated the same as certificate of gin server
+		entry.ManagementServer.TLSConfig = &tls.Config{
+			GetCertificate: entry.getMgmtCertReloader().GetCertificate,
+		}
+	}
+
+	// listener passed by parent process while restarting gracefully
+	ln, ok, err := inherited.listener(entry.managementListenerName())
+	if !ok {
+		ln, err = net.Listen("tcp", entry.ManagementServer.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry.managementListener = ln
+	return ln, nil
+}
+
+// serveManagement blocks and serves requests on management listener until server closed.
+func (entry *GinEntry) serveManagement(ln net.Listener) error {
+	if entry.IsManagementTlsEnabled() {
+		return entry.ManagementServer.ServeTLS(ln, "", "")
+	}
+
+	return entry.ManagementServer.Serve(ln)
+}
+
+// WithManagementPort provide port of dedicated management listener.
+func WithManagementPort(port uint64) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.ManagementPort = port
+	}
+}
+
+// WithManagementHost provide host which management listener would bind to.
+func WithManagementHost(host string) GinEntryOption {
+	return func(entry *GinEntry) {
+		if len(host) > 0 {
+			entry.ManagementHost = host
+		}
+	}
+}
+
+// WithManagementCertEntry provide rkentry.CertEntry of management listener.
+func WithManagementCertEntry(certEntry *rkentry.CertEntry) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.ManagementCertEntry = certEntry
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGinEntry_ManagementDisabled(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-mgmt-disabled"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.False(t, entry.IsManagementEnabled())
+	assert.Nil(t, entry.ManagementServer)
+	assert.Equal(t, entry.Router, entry.managementRouter())
+
+	scheme, port := entry.managementAddr()
+	assert.Equal(t, "http", scheme)
+	assert.Equal(t, entry.Port, port)
+}
+
+func TestGinEntry_Management(t *testing.T) {
+	entry := RegisterGinEntry(
+		WithName("ut-mgmt"),
+		WithPort(8085),
+		WithManagementPort(8086),
+		WithManagementHost("127.0.0.1"),
+		WithCommonServiceEntry(rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+			Enabled: true,
+		})),
+		WithPromEntry(rkentry.RegisterPromEntry(&rkentry.BootProm{
+			Enabled: true,
+		})))
+
+	assert.True(t, entry.IsManagementEnabled())
+	assert.Equal(t, "127.0.0.1:8086", entry.ManagementServer.Addr)
+
+	// business middleware which rejects everything
+	entry.AddMiddleware(func(ctx *gin.Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	entry.Bootstrap(context.TODO())
+	time.Sleep(time.Second)
+
+	// builtin endpoints are served by management listener without business middleware
+	resp, err := http.Get("http://127.0.0.1:8086/rk/v1/ready")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get("http://127.0.0.1:8086/metrics")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	// and are not registered on business router
+	for _, route := range entry.Router.Routes() {
+		assert.NotEqual(t, "/rk/v1/ready", route.Path)
+	}
+	resp, err = http.Get("http://127.0.0.1:8085/rk/v1/ready")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	entry.Interrupt(context.TODO())
+
+	// both listeners are closed
+	_, err = http.Get("http://127.0.0.1:8086/rk/v1/ready")
+	assert.NotNil(t, err)
+	_, err = http.Get("http://127.0.0.1:8085/rk/v1/ready")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,452 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	rkentry "github.com/rookie-ninja/rk-entry/v2/entry"
+	rkmidauth "github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	rkmidcors "github.com/rookie-ninja/rk-entry/v2/middleware/cors"
+	rkmidcsrf "github.com/rookie-ninja/rk-entry/v2/middleware/csrf"
+	rkmidjwt "github.com/rookie-ninja/rk-entry/v2/middleware/jwt"
+	rkmidlog "github.com/rookie-ninja/rk-entry/v2/middleware/log"
+	rkmidmeta "github.com/rookie-ninja/rk-entry/v2/middleware/meta"
+	rkmidpanic "github.com/rookie-ninja/rk-entry/v2/middleware/panic"
+	rkmidprom "github.com/rookie-ninja/rk-entry/v2/middleware/prom"
+	rkmidlimit "github.com/rookie-ninja/rk-entry/v2/middleware/ratelimit"
+	rkmidsec "github.com/rookie-ninja/rk-entry/v2/middleware/secure"
+	rkmidtimeout "github.com/rookie-ninja/rk-entry/v2/middleware/timeout"
+	rkmidtrace "github.com/rookie-ninja/rk-entry/v2/middleware/tracing"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/cors"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/csrf"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/gzip"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/jwt"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/log"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/meta"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/panic"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/prom"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/ratelimit"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/secure"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/timeout"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/tracing"
+	"strings"
+	"sync/atomic"
+)
+
+// Names of built-in middlewares which could be used in middleware.order.
+const (
+	MiddlewareLogging   = "logging"
+	MiddlewarePanic     = "panic"
+	MiddlewareProm      = "prom"
+	MiddlewareTrace     = "trace"
+	MiddlewareCors      = "cors"
+	MiddlewareJwt       = "jwt"
+	MiddlewareSecure    = "secure"
+	MiddlewareCsrf      = "csrf"
+	MiddlewareGzip      = "gzip"
+	MiddlewareMeta      = "meta"
+	MiddlewareAuth      = "auth"
+	MiddlewareTimeout   = "timeout"
+	MiddlewareRateLimit = "rateLimit"
+)
+
+// defaultMiddlewareOrder is the order of built-in middlewares if middleware.order is not provided.
+//
+// Panic middleware should be placed after logging middleware, we should make sure middlewares never panic.
+var defaultMiddlewareOrder = []string{
+	MiddlewareLogging,
+	MiddlewarePanic,
+	MiddlewareProm,
+	MiddlewareTrace,
+	MiddlewareCors,
+	MiddlewareJwt,
+	MiddlewareSecure,
+	MiddlewareCsrf,
+	MiddlewareGzip,
+	MiddlewareMeta,
+	MiddlewareAuth,
+	MiddlewareTimeout,
+	MiddlewareRateLimit,
+}
+
+// BootMiddleware bootstrap config of middlewares.
+//
+// Order reorders built-in and custom middlewares, names are case-insensitive. Middlewares missing in Order would be
+// appended in default order followed by custom middlewares, so list every enabled middleware in order to take full
+// control of the chain.
+type BootMiddleware struct {
+	Ignore     []string                `yaml:"ignore" json:"ignore"`
+	ErrorModel string                  `yaml:"errorModel" json:"errorModel"`
+	Order      []string                `yaml:"order" json:"order"`
+	Custom     []BootCustomMiddleware  `yaml:"custom" json:"custom"`
+	Logging    rkmidlog.BootConfig     `yaml:"logging" json:"logging"`
+	Prom       rkmidprom.BootConfig    `yaml:"prom" json:"prom"`
+	Auth       rkmidauth.BootConfig    `yaml:"auth" json:"auth"`
+	Cors       rkmidcors.BootConfig    `yaml:"cors" json:"cors"`
+	Meta       rkmidmeta.BootConfig    `yaml:"meta" json:"meta"`
+	Jwt        rkmidjwt.BootConfig     `yaml:"jwt" json:"jwt"`
+	Secure     rkmidsec.BootConfig     `yaml:"secure" json:"secure"`
+	RateLimit  rkmidlimit.BootConfig   `yaml:"rateLimit" json:"rateLimit"`
+	Csrf       rkmidcsrf.BootConfig    `yaml:"csrf" json:"csrf"`
+	Timeout    rkmidtimeout.BootConfig `yaml:"timeout" json:"timeout"`
+	Trace      rkmidtrace.BootConfig   `yaml:"trace" json:"trace"`
+	Gzip       struct {
+		Enabled bool     `yaml:"enabled" json:"enabled"`
+		Ignore  []string `yaml:"ignore" json:"ignore"`
+		Level   string   `yaml:"level" json:"level"`
+	} `yaml:"gzip" json:"gzip"`
+}
+
+// ResolveOrder returns names of all built-in and custom middlewares in the order they would be added.
+//
+// Error would be returned if unknown or duplicate names found in Order or Custom.
+func (boot *BootMiddleware) ResolveOrder() ([]string, error) {
+	known := append([]string{}, defaultMiddlewareOrder...)
+	for i := range boot.Custom {
+		name := strings.TrimSpace(boot.Custom[i].Name)
+		if len(name) < 1 {
+			return nil, fmt.Errorf("empty name of middleware.custom[%d]", i)
+		}
+
+		if len(findMiddlewareName(known, name)) > 0 {
+			return nil, fmt.Errorf("duplicate middleware %q in middleware.custom", name)
+		}
+
+		known = append(known, name)
+	}
+
+	res := make([]string, 0, len(known))
+	added := make(map[string]bool)
+
+	for _, name := range boot.Order {
+		found := findMiddlewareName(known, name)
+		if len(found) < 1 {
+			return nil, fmt.Errorf("unknown middleware %q in middleware.order, options: %s",
+				name, strings.Join(known, ", "))
+		}
+
+		if added[found] {
+			return nil, fmt.Errorf("duplicate middleware %q in middleware.order", name)
+		}
+
+		added[found] = true
+		res = append(res, found)
+	}
+
+	for _, name := range known {
+		if !added[name] {
+			res = append(res, name)
+		}
+	}
+
+	return res, nil
+}
+
+// ToMiddlewares creates enabled middlewares in resolved order, returns names and middlewares.
+func (boot *BootMiddleware) ToMiddlewares(entryName string,
+	loggerEntry *rkentry.LoggerEntry,
+	eventEntry *rkentry.EventEntry,
+	promRegistry *prometheus.Registry) ([]string, []gin.HandlerFunc, error) {
+	return boot.toMiddlewares(entryName, loggerEntry, eventEntry, promRegistry, true)
+}
+
+// toMiddlewares creates enabled middlewares in resolved order, panic middleware would be skipped if withPanic is false.
+func (boot *BootMiddleware) toMiddlewares(entryName string,
+	loggerEntry *rkentry.LoggerEntry,
+	eventEntry *rkentry.EventEntry,
+	promRegistry *prometheus.Registry,
+	withPanic bool) ([]string, []gin.HandlerFunc, error) {
+	order, err := boot.ResolveOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0)
+	mids := make([]gin.HandlerFunc, 0)
+
+	for _, name := range order {
+		if name == MiddlewarePanic && !withPanic {
+			continue
+		}
+
+		var mid gin.HandlerFunc
+		if custom := boot.getCustom(name); custom != nil {
+			if mid, err = custom.ToMiddleware(entryName); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			mid = boot.toMiddleware(name, entryName, loggerEntry, eventEntry, promRegistry)
+		}
+
+		if mid == nil {
+			continue
+		}
+
+		names = append(names, name)
+		mids = append(mids, mid)
+	}
+
+	return names, mids, nil
+}
+
+// toMiddleware creates built-in middleware with name, nil would be returned if middleware is disabled.
+//
+// Paths in Ignore are passed to each middleware together with its own ignored paths.
+func (boot *BootMiddleware) toMiddleware(name, entryName string,
+	loggerEntry *rkentry.LoggerEntry,
+	eventEntry *rkentry.EventEntry,
+	promRegistry *prometheus.Registry) gin.HandlerFunc {
+	switch name {
+	case MiddlewareLogging:
+		if boot.Logging.Enabled {
+			config := boot.Logging
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginlog.Middleware(
+				rkmidlog.ToOptions(&config, entryName, GinEntryType, loggerEntry, eventEntry)...)
+		}
+	case MiddlewarePanic:
+		// panic middleware is always enabled
+		return rkginpanic.Middleware(rkmidpanic.WithEntryNameAndType(entryName, GinEntryType))
+	case MiddlewareProm:
+		if boot.Prom.Enabled {
+			config := boot.Prom
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginprom.Middleware(
+				rkmidprom.ToOptions(&config, entryName, GinEntryType, promRegistry, rkmidprom.LabelerTypeHttp)...)
+		}
+	case MiddlewareTrace:
+		if boot.Trace.Enabled {
+			config := boot.Trace
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkgintrace.Middleware(rkmidtrace.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareCors:
+		if boot.Cors.Enabled {
+			config := boot.Cors
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkgincors.Middleware(rkmidcors.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareJwt:
+		if boot.Jwt.Enabled {
+			config := boot.Jwt
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginjwt.Middleware(rkmidjwt.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareSecure:
+		if boot.Secure.Enabled {
+			config := boot.Secure
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginsec.Middleware(rkmidsec.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareCsrf:
+		if boot.Csrf.Enabled {
+			config := boot.Csrf
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkgincsrf.Middleware(rkmidcsrf.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareGzip:
+		if boot.Gzip.Enabled {
+			return rkgingzip.Middleware(
+				rkgingzip.WithEntryNameAndType(entryName, GinEntryType),
+				rkgingzip.WithLevel(boot.Gzip.Level),
+				rkgingzip.WithPathToIgnore(boot.withIgnore(boot.Gzip.Ignore)...))
+		}
+	case MiddlewareMeta:
+		if boot.Meta.Enabled {
+			config := boot.Meta
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginmeta.Middleware(rkmidmeta.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareAuth:
+		if boot.Auth.Enabled {
+			config := boot.Auth
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginauth.Middleware(rkmidauth.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareTimeout:
+		if boot.Timeout.Enabled {
+			config := boot.Timeout
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkgintout.Middleware(rkmidtimeout.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	case MiddlewareRateLimit:
+		if boot.RateLimit.Enabled {
+			config := boot.RateLimit
+			config.Ignore = boot.withIgnore(config.Ignore)
+			return rkginlimit.Middleware(rkmidlimit.ToOptions(&config, entryName, GinEntryType)...)
+		}
+	}
+
+	return nil
+}
+
+// middlewareConfig returns effective config of middleware with name and whether it is enabled.
+//
+// Paths in Ignore are merged the same way as toMiddleware does, so configs could be compared while reloading.
+func (boot *BootMiddleware) middlewareConfig(name string) (interface{}, bool) {
+	if custom := boot.getCustom(name); custom != nil {
+		return *custom, custom.Enabled
+	}
+
+	switch name {
+	case MiddlewareLogging:
+		config := boot.Logging
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewarePanic:
+		return nil, true
+	case MiddlewareProm:
+		config := boot.Prom
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareTrace:
+		config := boot.Trace
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareCors:
+		config := boot.Cors
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareJwt:
+		config := boot.Jwt
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareSecure:
+		config := boot.Secure
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareCsrf:
+		config := boot.Csrf
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareGzip:
+		config := boot.Gzip
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareMeta:
+		config := boot.Meta
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareAuth:
+		config := boot.Auth
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareTimeout:
+		config := boot.Timeout
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	case MiddlewareRateLimit:
+		config := boot.RateLimit
+		config.Ignore = boot.withIgnore(config.Ignore)
+		return config, config.Enabled
+	}
+
+	return nil, false
+}
+
+// enabledMiddlewares returns names of enabled middlewares in resolved order without creating them.
+func (boot *BootMiddleware) enabledMiddlewares(withPanic bool) ([]string, error) {
+	order, err := boot.ResolveOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+	for _, name := range order {
+		if name == MiddlewarePanic && !withPanic {
+			continue
+		}
+
+		if _, enabled := boot.middlewareConfig(name); enabled {
+			res = append(res, name)
+		}
+	}
+
+	return res, nil
+}
+
+// withIgnore returns paths in Ignore followed by ignored paths of middleware.
+func (boot *BootMiddleware) withIgnore(ignore []string) []string {
+	res := make([]string, 0, len(boot.Ignore)+len(ignore))
+	for _, path := range append(append([]string{}, boot.Ignore...), ignore...) {
+		if len(path) > 0 {
+			res = append(res, path)
+		}
+	}
+
+	return res
+}
+
+// getCustom returns custom middleware config with name, nil if not found.
+func (boot *BootMiddleware) getCustom(name string) *BootCustomMiddleware {
+	for i := range boot.Custom {
+		if strings.TrimSpace(boot.Custom[i].Name) == name {
+			return &boot.Custom[i]
+		}
+	}
+
+	return nil
+}
+
+// findMiddlewareName returns name in names case-insensitively, empty string if not found.
+func findMiddlewareName(names []string, name string) string {
+	for _, candidate := range names {
+		if strings.EqualFold(strings.TrimSpace(name), candidate) {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// GetMiddlewareChain returns names of middlewares in the order they were added with names.
+//
+// Middlewares added with AddMiddleware are not included since they have no name.
+func (entry *GinEntry) GetMiddlewareChain() []string {
+	return append([]string{}, entry.middlewareChain...)
+}
+
+// AddNamedMiddleware add middleware with name which would be exposed by GetMiddlewareChain.
+// This function should be called before Bootstrap() called.
+//
+// Named middlewares are served behind a slot, so that ReloadMiddleware could swap them without touching router.
+func (entry *GinEntry) AddNamedMiddleware(name string, mid gin.HandlerFunc) {
+	if entry.namedMiddlewares == nil {
+		entry.namedMiddlewares = make(map[string]*middlewareSlot)
+	}
+
+	slot := newMiddlewareSlot(mid)
+	entry.middlewareChain = append(entry.middlewareChain, name)
+	entry.namedMiddlewares[name] = slot
+	entry.Router.Use(slot.handle)
+}
+
+// middlewareSlot holds a middleware which could be swapped atomically.
+//
+// Requests already dispatched keep running with the middleware they loaded, new requests pick up the swapped one.
+type middlewareSlot struct {
+	mid atomic.Value
+}
+
+// newMiddlewareSlot creates middlewareSlot with middleware.
+func newMiddlewareSlot(mid gin.HandlerFunc) *middlewareSlot {
+	slot := &middlewareSlot{}
+	slot.store(mid)
+	return slot
+}
+
+// handle calls current middleware.
+func (slot *middlewareSlot) handle(ctx *gin.Context) {
+	slot.mid.Load().(gin.HandlerFunc)(ctx)
+}
+
+// store swaps current middleware.
+func (slot *middlewareSlot) store(mid gin.HandlerFunc) {
+	slot.mid.Store(mid)
+}
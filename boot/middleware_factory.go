@@ -0,0 +1,121 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"sync"
+)
+
+var (
+	middlewareFactories     = make(map[string]MiddlewareFactory)
+	middlewareFactoriesLock sync.RWMutex
+)
+
+// MiddlewareFactory creates middleware with raw config under middleware.custom[].config in boot YAML.
+//
+// Keys in raw config are lower-cased while parsing boot YAML, nested maps are converted into map[string]interface{}.
+type MiddlewareFactory func(raw map[string]interface{}, entryName string) (gin.HandlerFunc, error)
+
+// BootCustomMiddleware bootstrap config of middleware created by MiddlewareFactory registered with the same name.
+type BootCustomMiddleware struct {
+	Name    string                 `yaml:"name" json:"name"`
+	Enabled bool                   `yaml:"enabled" json:"enabled"`
+	Config  map[string]interface{} `yaml:"config" json:"config"`
+}
+
+// ToMiddleware creates middleware with registered factory, nil would be returned if disabled.
+func (boot *BootCustomMiddleware) ToMiddleware(entryName string) (gin.HandlerFunc, error) {
+	if !boot.Enabled {
+		return nil, nil
+	}
+
+	factory := GetMiddlewareFactory(boot.Name)
+	if factory == nil {
+		return nil, fmt.Errorf("middleware factory %q not registered, use rkgin.RegisterMiddlewareFactory()", boot.Name)
+	}
+
+	raw := toStringKeyMap(boot.Config)
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+
+	mid, err := factory(raw, entryName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create middleware %q, %v", boot.Name, err)
+	}
+
+	if mid == nil {
+		return nil, fmt.Errorf("nil middleware created by factory %q", boot.Name)
+	}
+
+	return mid, nil
+}
+
+// RegisterMiddlewareFactory register factory of custom middleware which could be enabled in middleware.custom
+// of boot YAML, factory registered with the same name would be overridden.
+//
+// This function should be called before RegisterGinEntryYAML() called, init() function is recommended.
+// Panic would be raised if name is empty, conflicts with built-in middlewares or factory is nil.
+func RegisterMiddlewareFactory(name string, f MiddlewareFactory) {
+	if len(name) < 1 || f == nil {
+		panic("rkgin: empty name or nil factory of middleware")
+	}
+
+	if len(findMiddlewareName(defaultMiddlewareOrder, name)) > 0 {
+		panic(fmt.Sprintf("rkgin: middleware factory %q conflicts with built-in middleware", name))
+	}
+
+	middlewareFactoriesLock.Lock()
+	defer middlewareFactoriesLock.Unlock()
+
+	middlewareFactories[name] = f
+}
+
+// GetMiddlewareFactory returns registered factory of custom middleware, nil if not found.
+func GetMiddlewareFactory(name string) MiddlewareFactory {
+	middlewareFactoriesLock.RLock()
+	defer middlewareFactoriesLock.RUnlock()
+
+	return middlewareFactories[name]
+}
+
+// toStringKeyMap converts map[interface{}]interface{} decoded from YAML into map[string]interface{} recursively.
+func toStringKeyMap(src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+
+	res := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		res[k] = toStringKeyValue(v)
+	}
+
+	return res
+}
+
+// toStringKeyValue converts nested maps in value into map[string]interface{}.
+func toStringKeyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, inner := range val {
+			res[fmt.Sprintf("%v", k)] = toStringKeyValue(inner)
+		}
+		return res
+	case map[string]interface{}:
+		return toStringKeyMap(val)
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i := range val {
+			res[i] = toStringKeyValue(val[i])
+		}
+		return res
+	}
+
+	return v
+}
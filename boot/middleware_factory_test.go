@@ -0,0 +1,121 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterMiddlewareFactory(t *testing.T) {
+	factory := func(raw map[string]interface{}, entryName string) (gin.HandlerFunc, error) {
+		return func(ctx *gin.Context) {}, nil
+	}
+
+	RegisterMiddlewareFactory("ut-factory", factory)
+	assert.NotNil(t, GetMiddlewareFactory("ut-factory"))
+	assert.Nil(t, GetMiddlewareFactory("ut-factory-missing"))
+
+	// conflicts with built-in middleware
+	defer assertPanic(t)
+	RegisterMiddlewareFactory("rateLimit", factory)
+}
+
+func TestBootCustomMiddleware_ToMiddleware(t *testing.T) {
+	RegisterMiddlewareFactory("ut-custom-error", func(map[string]interface{}, string) (gin.HandlerFunc, error) {
+		return nil, errors.New("ut-error")
+	})
+
+	// disabled
+	mid, err := (&BootCustomMiddleware{Name: "ut-custom-missing"}).ToMiddleware("ut-entry")
+	assert.Nil(t, err)
+	assert.Nil(t, mid)
+
+	// factory not registered
+	_, err = (&BootCustomMiddleware{Name: "ut-custom-missing", Enabled: true}).ToMiddleware("ut-entry")
+	assert.NotNil(t, err)
+
+	// factory returns error
+	_, err = (&BootCustomMiddleware{Name: "ut-custom-error", Enabled: true}).ToMiddleware("ut-entry")
+	assert.NotNil(t, err)
+}
+
+func TestToStringKeyMap(t *testing.T) {
+	assert.Nil(t, toStringKeyMap(nil))
+
+	res := toStringKeyMap(map[string]interface{}{
+		"outer": map[interface{}]interface{}{
+			"inner": "value",
+			1:       []interface{}{map[interface{}]interface{}{"key": true}},
+		},
+	})
+	outer := res["outer"].(map[string]interface{})
+	assert.Equal(t, "value", outer["inner"])
+	assert.Equal(t, true, outer["1"].([]interface{})[0].(map[string]interface{})["key"])
+}
+
+func TestRegisterGinEntryYAML_WithCustomMiddleware(t *testing.T) {
+	RegisterMiddlewareFactory("ut-tenant", func(raw map[string]interface{}, entryName string) (gin.HandlerFunc, error) {
+		header := raw["header"].(string)
+		tenants := raw["tenants"].(map[string]interface{})
+
+		return func(ctx *gin.Context) {
+			ctx.Header(header, entryName+":"+tenants["default"].(string))
+		}, nil
+	})
+
+	bootStr := `
+gin:
+  - name: ut-custom-mid
+    port: 1949
+    enabled: true
+    middleware:
+      order: ["ut-tenant", "logging"]
+      logging:
+        enabled: true
+      custom:
+        - name: ut-tenant
+          enabled: true
+          config:
+            header: X-Tenant
+            tenants:
+              default: rk
+        - name: ut-audit
+          enabled: false
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-custom-mid"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, []string{"ut-tenant", "logging", "panic"}, entry.GetMiddlewareChain())
+
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	writer := httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/ut", nil))
+	assert.Equal(t, "ut-custom-mid:rk", writer.Header().Get("X-Tenant"))
+
+	// factory not registered
+	bootStr = `
+gin:
+  - name: ut-custom-mid-missing
+    port: 1949
+    enabled: true
+    middleware:
+      custom:
+        - name: ut-missing
+          enabled: true
+`
+	defer assertPanic(t)
+	RegisterGinEntryYAML([]byte(bootStr))
+}
@@ -0,0 +1,138 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBootMiddleware_ResolveOrder(t *testing.T) {
+	// default
+	order, err := (&BootMiddleware{}).ResolveOrder()
+	assert.Nil(t, err)
+	assert.Equal(t, defaultMiddlewareOrder, order)
+
+	// reorder, missing ones are appended in default order
+	order, err = (&BootMiddleware{Order: []string{"ratelimit", "Logging", "jwt"}}).ResolveOrder()
+	assert.Nil(t, err)
+	assert.Len(t, order, len(defaultMiddlewareOrder))
+	assert.Equal(t, []string{MiddlewareRateLimit, MiddlewareLogging, MiddlewareJwt, MiddlewarePanic, MiddlewareProm},
+		order[:5])
+	assert.Equal(t, MiddlewareTimeout, order[len(order)-1])
+
+	// unknown
+	_, err = (&BootMiddleware{Order: []string{"logging", "unknown"}}).ResolveOrder()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown")
+
+	// duplicate
+	_, err = (&BootMiddleware{Order: []string{"jwt", "JWT"}}).ResolveOrder()
+	assert.NotNil(t, err)
+
+	// custom middlewares are appended after built-in ones
+	boot := &BootMiddleware{
+		Order:  []string{"ut-audit", "logging"},
+		Custom: []BootCustomMiddleware{{Name: "ut-tenant"}, {Name: "ut-audit"}},
+	}
+	order, err = boot.ResolveOrder()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ut-audit", MiddlewareLogging, MiddlewarePanic}, order[:3])
+	assert.Equal(t, "ut-tenant", order[len(order)-1])
+
+	// custom middleware conflicts with built-in one
+	_, err = (&BootMiddleware{Custom: []BootCustomMiddleware{{Name: "JWT"}}}).ResolveOrder()
+	assert.NotNil(t, err)
+
+	// custom middleware without name
+	_, err = (&BootMiddleware{Custom: []BootCustomMiddleware{{}}}).ResolveOrder()
+	assert.NotNil(t, err)
+}
+
+func TestBootMiddleware_ToMiddlewares(t *testing.T) {
+	// panic middleware is always enabled
+	boot := &BootMiddleware{}
+	names, mids, err := boot.ToMiddlewares("ut-mid", rkentry.LoggerEntryStdout, rkentry.EventEntryStdout,
+		prometheus.NewRegistry())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{MiddlewarePanic}, names)
+	assert.Len(t, mids, 1)
+
+	// with order
+	boot.Order = []string{MiddlewareRateLimit, MiddlewareJwt}
+	boot.Jwt.Enabled = true
+	boot.RateLimit.Enabled = true
+	boot.Logging.Enabled = true
+	names, mids, err = boot.ToMiddlewares("ut-mid", rkentry.LoggerEntryStdout, rkentry.EventEntryStdout,
+		prometheus.NewRegistry())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{MiddlewareRateLimit, MiddlewareJwt, MiddlewareLogging, MiddlewarePanic}, names)
+	assert.Len(t, mids, 4)
+
+	// with invalid order
+	boot.Order = []string{"invalid"}
+	_, _, err = boot.ToMiddlewares("ut-mid", rkentry.LoggerEntryStdout, rkentry.EventEntryStdout,
+		prometheus.NewRegistry())
+	assert.NotNil(t, err)
+}
+
+func TestRegisterGinEntryYAML_WithMiddlewareOrder(t *testing.T) {
+	// default order
+	bootStr := `
+gin:
+  - name: ut-mid-default
+    port: 1949
+    enabled: true
+    middleware:
+      jwt:
+        enabled: true
+      ratelimit:
+        enabled: true
+      logging:
+        enabled: true
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-mid-default"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, []string{"logging", "panic", "jwt", "rateLimit"}, entry.GetMiddlewareChain())
+	assert.Len(t, entry.Router.Handlers, 4)
+	assert.Contains(t, entry.String(), `"middlewareChain":["logging","panic","jwt","rateLimit"]`)
+
+	// with order
+	bootStr = `
+gin:
+  - name: ut-mid-order
+    port: 1949
+    enabled: true
+    middleware:
+      order: ["logging", "panic", "rateLimit", "jwt"]
+      jwt:
+        enabled: true
+      ratelimit:
+        enabled: true
+      logging:
+        enabled: true
+`
+	entry = RegisterGinEntryYAML([]byte(bootStr))["ut-mid-order"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, []string{"logging", "panic", "rateLimit", "jwt"}, entry.GetMiddlewareChain())
+
+	// with unknown middleware
+	bootStr = `
+gin:
+  - name: ut-mid-unknown
+    port: 1949
+    enabled: true
+    middleware:
+      order: ["logging", "unknown"]
+`
+	defer assertPanic(t)
+	RegisterGinEntryYAML([]byte(bootStr))
+}
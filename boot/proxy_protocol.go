@@ -0,0 +1,368 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultProxyHeaderTimeout max duration of reading PROXY protocol header
+	defaultProxyHeaderTimeout = 5 * time.Second
+	// proxyV1MaxLength max length of PROXY protocol v1 header including CRLF
+	proxyV1MaxLength = 107
+)
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// BootProxyProtocol bootstrap config of HAProxy PROXY protocol.
+//
+// PROXY protocol v1 and v2 headers would be parsed from connections of trusted sources, and the real client
+// address would be used as remote address of requests. TrustedCidrs is required once enabled, since header from
+// untrusted sources would let clients spoof their addresses. Connections from trusted sources without header are
+// accepted as they are.
+type BootProxyProtocol struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled"`
+	TrustedCidrs    []string `yaml:"trustedCidrs" json:"trustedCidrs"`
+	HeaderTimeoutMs int      `yaml:"headerTimeoutMs" json:"headerTimeoutMs"`
+}
+
+// ToOptions convert BootProxyProtocol into GinEntryOption list.
+func (boot *BootProxyProtocol) ToOptions() ([]GinEntryOption, error) {
+	if !boot.Enabled {
+		return []GinEntryOption{}, nil
+	}
+
+	if len(boot.TrustedCidrs) < 1 {
+		return nil, errors.New("empty trustedCidrs of enabled PROXY protocol")
+	}
+
+	trusted, err := ParseCidrs(boot.TrustedCidrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return []GinEntryOption{
+		WithProxyProtocol(time.Duration(boot.HeaderTimeoutMs)*time.Millisecond, trusted...),
+	}, nil
+}
+
+// ParseCidrs parses CIDRs like 10.0.0.0/8, single IP would be treated as /32 or /128.
+func ParseCidrs(cidrs ...string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR %q", cidr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", cidr)
+		}
+		res = append(res, ipNet)
+	}
+
+	return res, nil
+}
+
+// IsProxyProtocolEnabled Is PROXY protocol enabled?
+func (entry *GinEntry) IsProxyProtocolEnabled() bool {
+	return entry.proxyProtocol
+}
+
+// proxyProtocolListener parses PROXY protocol header of connections from trusted sources.
+type proxyProtocolListener struct {
+	net.Listener
+	trusted       []*net.IPNet
+	headerTimeout time.Duration
+}
+
+// Accept wraps connection from trusted source, header is parsed lazily in goroutine of connection.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.isTrusted(conn.RemoteAddr()) {
+		return conn, nil
+	}
+
+	return &proxyProtocolConn{
+		Conn:          conn,
+		reader:        bufio.NewReader(conn),
+		headerTimeout: l.headerTimeout,
+	}, nil
+}
+
+// isTrusted checks whether connection comes from trusted sources, no source is trusted if trusted is empty.
+func (l *proxyProtocolListener) isTrusted(addr net.Addr) bool {
+	if len(l.trusted) < 1 {
+		return false
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		// unix socket is local
+		return true
+	}
+
+	for i := range l.trusted {
+		if l.trusted[i].Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyProtocolConn overrides addresses with the ones in PROXY protocol header.
+type proxyProtocolConn struct {
+	net.Conn
+	reader        *bufio.Reader
+	headerTimeout time.Duration
+	once          sync.Once
+	lock          sync.Mutex
+	readDeadline  time.Time
+	remoteAddr    net.Addr
+	localAddr     net.Addr
+	err           error
+}
+
+// Read reads from connection after header parsed.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.parse()
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns source address in header, or address of connection if missing.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.parse()
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns destination address in header, or address of connection if missing.
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.parse()
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// SetDeadline records read deadline, so that it could be restored after header parsed.
+func (c *proxyProtocolConn) SetDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.readDeadline = t
+	c.lock.Unlock()
+
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline records read deadline, so that it could be restored after header parsed.
+func (c *proxyProtocolConn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.readDeadline = t
+	c.lock.Unlock()
+
+	return c.Conn.SetReadDeadline(t)
+}
+
+// parse reads header once with header timeout.
+func (c *proxyProtocolConn) parse() {
+	c.once.Do(func() {
+		timeout := c.headerTimeout
+		if timeout <= 0 {
+			timeout = defaultProxyHeaderTimeout
+		}
+		c.Conn.SetReadDeadline(time.Now().Add(timeout))
+
+		c.remoteAddr, c.localAddr, c.err = readProxyHeader(c.reader)
+
+		c.lock.Lock()
+		c.Conn.SetReadDeadline(c.readDeadline)
+		c.lock.Unlock()
+
+		if c.err != nil {
+			c.err = fmt.Errorf("invalid PROXY protocol header from %s, %v", c.Conn.RemoteAddr(), c.err)
+			c.Conn.Close()
+		}
+	})
+}
+
+// readProxyHeader reads PROXY protocol v1 or v2 header, nil addresses would be returned if header is missing
+// or addresses are not provided like LOCAL command or UNKNOWN protocol.
+func readProxyHeader(reader *bufio.Reader) (net.Addr, net.Addr, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch first[0] {
+	case proxyV1Prefix[0]:
+		if prefix, err := reader.Peek(len(proxyV1Prefix)); err == nil && bytes.Equal(prefix, proxyV1Prefix) {
+			return readProxyHeaderV1(reader)
+		}
+	case proxyV2Signature[0]:
+		if sig, err := reader.Peek(len(proxyV2Signature)); err == nil && bytes.Equal(sig, proxyV2Signature) {
+			return readProxyHeaderV2(reader)
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// readProxyHeaderV1 reads human-readable header like: PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n
+func readProxyHeaderV1(reader *bufio.Reader) (net.Addr, net.Addr, error) {
+	line := make([]byte, 0, proxyV1MaxLength)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyV1MaxLength {
+			return nil, nil, errors.New("v1 header is too long")
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("v1 header does not end with CRLF")
+	}
+
+	tokens := strings.Split(string(line[:len(line)-2]), " ")
+	if len(tokens) >= 2 && tokens[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+
+	if len(tokens) != 6 || (tokens[1] != "TCP4" && tokens[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("malformed v1 header %q", string(line))
+	}
+
+	src, err := parseProxyAddrV1(tokens[2], tokens[4])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, err := parseProxyAddrV1(tokens[3], tokens[5])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, dst, nil
+}
+
+// parseProxyAddrV1 parses IP and port in v1 header.
+func parseProxyAddrV1(ipStr, portStr string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %q in v1 header", ipStr)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q in v1 header", portStr)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads binary header.
+func readProxyHeaderV2(reader *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, nil, err
+	}
+
+	if header[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("unsupported v2 version %d", header[12]>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, nil, err
+	}
+
+	switch header[12] & 0x0F {
+	case 0x0:
+		// LOCAL command, connection established by proxy itself
+		return nil, nil, nil
+	case 0x1:
+		// PROXY command
+	default:
+		return nil, nil, fmt.Errorf("unsupported v2 command %d", header[12]&0x0F)
+	}
+
+	// transport protocol is ignored, since only addresses are needed
+	switch header[13] >> 4 {
+	case 0x1:
+		if len(payload) < 12 {
+			return nil, nil, errors.New("v2 header is too short for IPv4")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))},
+			&net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}, nil
+	case 0x2:
+		if len(payload) < 36 {
+			return nil, nil, errors.New("v2 header is too short for IPv6")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))},
+			&net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}, nil
+	}
+
+	// AF_UNSPEC and AF_UNIX, keep addresses of connection
+	return nil, nil, nil
+}
+
+// WithProxyProtocol enable PROXY protocol with header timeout and trusted sources.
+//
+// Header is not parsed from any source if trusted is empty.
+func WithProxyProtocol(headerTimeout time.Duration, trusted ...*net.IPNet) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.proxyProtocol = true
+		entry.proxyTrustedCidrs = append(entry.proxyTrustedCidrs, trusted...)
+		entry.proxyHeaderTimeout = defaultProxyHeaderTimeout
+		if headerTimeout > 0 {
+			entry.proxyHeaderTimeout = headerTimeout
+		}
+	}
+}
@@ -0,0 +1,216 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCidrs(t *testing.T) {
+	cidrs, err := ParseCidrs("10.0.0.0/8", "127.0.0.1", "::1")
+	assert.Nil(t, err)
+	assert.Len(t, cidrs, 3)
+	assert.True(t, cidrs[0].Contains(net.ParseIP("10.1.2.3")))
+	assert.True(t, cidrs[1].Contains(net.ParseIP("127.0.0.1")))
+	assert.False(t, cidrs[1].Contains(net.ParseIP("127.0.0.2")))
+	assert.True(t, cidrs[2].Contains(net.ParseIP("::1")))
+
+	_, err = ParseCidrs("invalid")
+	assert.NotNil(t, err)
+	_, err = ParseCidrs("10.0.0.0/99")
+	assert.NotNil(t, err)
+}
+
+func TestBootProxyProtocol_ToOptions(t *testing.T) {
+	// disabled
+	opts, err := (&BootProxyProtocol{TrustedCidrs: []string{"invalid"}}).ToOptions()
+	assert.Nil(t, err)
+	assert.Empty(t, opts)
+
+	// empty trusted CIDRs
+	_, err = (&BootProxyProtocol{Enabled: true}).ToOptions()
+	assert.NotNil(t, err)
+
+	// invalid CIDR
+	_, err = (&BootProxyProtocol{Enabled: true, TrustedCidrs: []string{"invalid"}}).ToOptions()
+	assert.NotNil(t, err)
+
+	// happy case
+	opts, err = (&BootProxyProtocol{Enabled: true, TrustedCidrs: []string{"10.0.0.0/8"}}).ToOptions()
+	assert.Nil(t, err)
+	entry := &GinEntry{}
+	for i := range opts {
+		opts[i](entry)
+	}
+	assert.True(t, entry.IsProxyProtocolEnabled())
+	assert.Len(t, entry.proxyTrustedCidrs, 1)
+	assert.Equal(t, defaultProxyHeaderTimeout, entry.proxyHeaderTimeout)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	// without header
+	src, dst, err := readProxyHeader(bufio.NewReader(bytes.NewBufferString("GET / HTTP/1.1\r\n")))
+	assert.Nil(t, err)
+	assert.Nil(t, src)
+	assert.Nil(t, dst)
+
+	// v1
+	reader := bufio.NewReader(bytes.NewBufferString("PROXY TCP4 1.2.3.4 5.6.7.8 1000 443\r\nGET"))
+	src, dst, err = readProxyHeader(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2.3.4:1000", src.String())
+	assert.Equal(t, "5.6.7.8:443", dst.String())
+	rest, _ := io.ReadAll(reader)
+	assert.Equal(t, "GET", string(rest))
+
+	// v1 with IPv6
+	src, _, err = readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY TCP6 ::1 ::2 1000 443\r\n")))
+	assert.Nil(t, err)
+	assert.Equal(t, "[::1]:1000", src.String())
+
+	// v1 unknown
+	src, _, err = readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY UNKNOWN\r\n")))
+	assert.Nil(t, err)
+	assert.Nil(t, src)
+
+	// v1 malformed
+	_, _, err = readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY TCP4 1.2.3.4\r\n")))
+	assert.NotNil(t, err)
+	_, _, err = readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY TCP4 x 5.6.7.8 1000 443\r\n")))
+	assert.NotNil(t, err)
+	_, _, err = readProxyHeader(bufio.NewReader(bytes.NewBuffer(append([]byte("PROXY "), make([]byte, 200)...))))
+	assert.NotNil(t, err)
+
+	// v2 IPv4
+	reader = bufio.NewReader(bytes.NewBuffer(append(proxyV2Header(0x21, 0x11,
+		[]byte{1, 2, 3, 4}, []byte{5, 6, 7, 8}, 1000, 443), []byte("GET")...)))
+	src, dst, err = readProxyHeader(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.2.3.4:1000", src.String())
+	assert.Equal(t, "5.6.7.8:443", dst.String())
+	rest, _ = io.ReadAll(reader)
+	assert.Equal(t, "GET", string(rest))
+
+	// v2 IPv6
+	src, _, err = readProxyHeader(bufio.NewReader(bytes.NewBuffer(proxyV2Header(0x21, 0x21,
+		net.ParseIP("::1"), net.ParseIP("::2"), 1000, 443))))
+	assert.Nil(t, err)
+	assert.Equal(t, "[::1]:1000", src.String())
+
+	// v2 LOCAL
+	src, _, err = readProxyHeader(bufio.NewReader(bytes.NewBuffer(proxyV2Header(0x20, 0x11,
+		[]byte{1, 2, 3, 4}, []byte{5, 6, 7, 8}, 1000, 443))))
+	assert.Nil(t, err)
+	assert.Nil(t, src)
+
+	// v2 invalid version
+	_, _, err = readProxyHeader(bufio.NewReader(bytes.NewBuffer(proxyV2Header(0x11, 0x11,
+		[]byte{1, 2, 3, 4}, []byte{5, 6, 7, 8}, 1000, 443))))
+	assert.NotNil(t, err)
+}
+
+func TestGinEntry_ProxyProtocol(t *testing.T) {
+	trusted, _ := ParseCidrs("127.0.0.1")
+	entry := RegisterGinEntry(
+		WithName("ut-proxy-protocol"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithProxyProtocol(time.Second, trusted...))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.ClientIP())
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	addr := entry.ListenAddr().String()
+
+	// v1
+	status, body := proxyProtocolRequest(t, addr, []byte("PROXY TCP4 1.2.3.4 5.6.7.8 1000 443\r\n"))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "1.2.3.4", body)
+
+	// v2
+	status, body = proxyProtocolRequest(t, addr, proxyV2Header(0x21, 0x11,
+		[]byte{4, 3, 2, 1}, []byte{5, 6, 7, 8}, 1000, 443))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "4.3.2.1", body)
+
+	// without header
+	status, body = proxyProtocolRequest(t, addr, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "127.0.0.1", body)
+}
+
+func TestGinEntry_ProxyProtocol_Untrusted(t *testing.T) {
+	trusted, _ := ParseCidrs("10.0.0.0/8")
+	entry := RegisterGinEntry(
+		WithName("ut-proxy-protocol-untrusted"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithProxyProtocol(time.Second, trusted...))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.ClientIP())
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// header from untrusted source is not parsed
+	status, _ := proxyProtocolRequest(t, entry.ListenAddr().String(), []byte("PROXY TCP4 1.2.3.4 5.6.7.8 1000 443\r\n"))
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// no source is trusted if empty
+	assert.False(t, (&proxyProtocolListener{}).isTrusted(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}))
+}
+
+func proxyV2Header(verCmd, family byte, src, dst net.IP, srcPort, dstPort uint16) []byte {
+	ports := make([]byte, 4)
+	binary.BigEndian.PutUint16(ports[0:2], srcPort)
+	binary.BigEndian.PutUint16(ports[2:4], dstPort)
+	payload := append(append(append([]byte{}, src...), dst...), ports...)
+
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(payload)))
+	header := append(append([]byte{}, proxyV2Signature...), verCmd, family)
+	header = append(header, length...)
+
+	return append(header, payload...)
+}
+
+func proxyProtocolRequest(t *testing.T, addr string, header []byte) (int, string) {
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	if conn == nil {
+		return 0, ""
+	}
+	defer conn.Close()
+
+	conn.Write(header)
+	conn.Write([]byte("GET /ut HTTP/1.1\r\nHost: localhost\r\nConnection: close\r\n\r\n"))
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	assert.Nil(t, err)
+	if resp == nil {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// BootMiddlewareReload bootstrap config of reloading middlewares from boot config file.
+//
+// Once enabled, middlewares of entry and route groups would be reloaded from file in path on SIGHUP.
+// File would be checked for modification with intervalMs as well if intervalMs is positive.
+type BootMiddlewareReload struct {
+	Enabled    bool   `yaml:"enabled" json:"enabled"`
+	Path       string `yaml:"path" json:"path"`
+	IntervalMs int    `yaml:"intervalMs" json:"intervalMs"`
+}
+
+// ToOptions validates config and converts it into GinEntryOption.
+func (boot *BootMiddlewareReload) ToOptions() ([]GinEntryOption, error) {
+	if !boot.Enabled {
+		return []GinEntryOption{}, nil
+	}
+
+	if len(boot.Path) < 1 {
+		return nil, errors.New("path of boot config file is required if middleware reload is enabled")
+	}
+
+	if boot.IntervalMs < 0 {
+		return nil, fmt.Errorf("negative intervalMs %d of middleware reload", boot.IntervalMs)
+	}
+
+	return []GinEntryOption{
+		WithMiddlewareReload(boot.Path, time.Duration(boot.IntervalMs)*time.Millisecond),
+	}, nil
+}
+
+// WithMiddlewareReload reload middlewares from boot config file in path on SIGHUP.
+//
+// File would be checked for modification with interval as well if interval is positive.
+func WithMiddlewareReload(path string, interval time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.reloadPath = path
+		entry.reloadInterval = interval
+	}
+}
+
+// IsMiddlewareReloadEnabled Is middleware reload enabled?
+func (entry *GinEntry) IsMiddlewareReloadEnabled() bool {
+	return len(entry.reloadPath) > 0
+}
+
+// ReloadMiddleware rebuilds middlewares of entry and route groups from boot YAML and swaps them atomically.
+//
+// Config of entry is looked up by name of entry, and decoded the same way as RegisterGinEntryYAML does. Only options
+// of middlewares could be reloaded, error would be returned without touching any middleware if anything else changed,
+// like port, tls or listener, or if middlewares were enabled, disabled or reordered. Options of prom and trace
+// middlewares and errorModel could not be reloaded either, since they own collectors and exporters.
+//
+// Requests in flight keep running with old middlewares.
+func (entry *GinEntry) ReloadMiddleware(raw []byte) error {
+	event, logger := entry.logBasicInfo("ReloadMiddleware", context.Background())
+
+	reloaded, err := entry.reloadMiddleware(raw)
+	if err != nil {
+		event.AddErr(err)
+		logger.Warn("Error occurs while reloading middlewares.", event.ListPayloads()...)
+		entry.EventEntry.FinishWithCond(event, false)
+		return err
+	}
+
+	event.AddPayloads(zap.Strings("reloadedMiddlewares", reloaded))
+	logger.Info("Middlewares reloaded.", zap.Strings("reloadedMiddlewares", reloaded))
+	entry.EventEntry.Finish(event)
+
+	return nil
+}
+
+// reloadMiddleware swaps changed middlewares and returns paths of them, like middleware.cors or routeGroups[0].middleware.jwt
+func (entry *GinEntry) reloadMiddleware(raw []byte) ([]string, error) {
+	entry.reloadLock.Lock()
+	defer entry.reloadLock.Unlock()
+
+	prev := entry.bootConfig
+	if prev == nil {
+		return nil, fmt.Errorf("gin entry %q was not registered with boot config", entry.entryName)
+	}
+
+	if errs := ValidateBootGin(raw); len(errs) > 0 {
+		return nil, joinValidationErrors(errs)
+	}
+
+	config := &BootGin{}
+	rkentry.UnmarshalBootYAML(raw, config)
+
+	var next *BootGinElement
+	for _, element := range config.Gin {
+		if element != nil && element.Enabled && element.Name == entry.entryName {
+			next = element
+		}
+	}
+
+	if next == nil {
+		return nil, fmt.Errorf("enabled gin entry %q not found in boot config", entry.entryName)
+	}
+
+	if err := checkReloadable(prev, next); err != nil {
+		return nil, fmt.Errorf("gin entry %q: %v", entry.entryName, err)
+	}
+
+	// 1: create changed middlewares, nothing is swapped until all of them are created
+	reloaded := make([]string, 0)
+	swaps := make([]func(), 0)
+
+	paths, funcs, err := entry.reloadChain("middleware", &prev.Middleware, &next.Middleware, entry.namedMiddlewares, true)
+	if err != nil {
+		return nil, fmt.Errorf("gin entry %q: %v", entry.entryName, err)
+	}
+	reloaded = append(reloaded, paths...)
+	swaps = append(swaps, funcs...)
+
+	for i := range next.RouteGroups {
+		paths, funcs, err := entry.reloadChain(fmt.Sprintf("routeGroups[%d].middleware", i),
+			&prev.RouteGroups[i].Middleware, &next.RouteGroups[i].Middleware, entry.routeGroups[i].slots, false)
+		if err != nil {
+			return nil, fmt.Errorf("gin entry %q: %v", entry.entryName, err)
+		}
+		reloaded = append(reloaded, paths...)
+		swaps = append(swaps, funcs...)
+	}
+
+	// 2: swap
+	for i := range swaps {
+		swaps[i]()
+	}
+
+	entry.bootConfig = next
+
+	return reloaded, nil
+}
+
+// reloadChain creates middlewares whose options changed, and returns their paths and functions which swap them.
+func (entry *GinEntry) reloadChain(path string,
+	prev, next *BootMiddleware,
+	slots map[string]*middlewareSlot,
+	withPanic bool) ([]string, []func(), error) {
+	if prev.ErrorModel != next.ErrorModel {
+		return nil, nil, fmt.Errorf("%s.errorModel could not be reloaded, restart is required", path)
+	}
+
+	prevNames, err := prev.enabledMiddlewares(withPanic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nextNames, err := next.enabledMiddlewares(withPanic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !reflect.DeepEqual(prevNames, nextNames) {
+		return nil, nil, fmt.Errorf("enabled middlewares of %s changed from [%s] to [%s], restart is required",
+			path, strings.Join(prevNames, ", "), strings.Join(nextNames, ", "))
+	}
+
+	paths := make([]string, 0)
+	swaps := make([]func(), 0)
+
+	for _, name := range nextNames {
+		prevConfig, _ := prev.middlewareConfig(name)
+		nextConfig, _ := next.middlewareConfig(name)
+		if reflect.DeepEqual(prevConfig, nextConfig) {
+			continue
+		}
+
+		if name == MiddlewareProm || name == MiddlewareTrace {
+			return nil, nil, fmt.Errorf("%s.%s could not be reloaded, restart is required", path, name)
+		}
+
+		slot, ok := slots[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("middleware %s.%s was not added with name", path, name)
+		}
+
+		mid, err := entry.newMiddleware(next, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		paths = append(paths, path+"."+name)
+		swaps = append(swaps, func() {
+			slot.store(mid)
+		})
+	}
+
+	return paths, swaps, nil
+}
+
+// newMiddleware creates enabled built-in or custom middleware with name.
+func (entry *GinEntry) newMiddleware(boot *BootMiddleware, name string) (gin.HandlerFunc, error) {
+	if custom := boot.getCustom(name); custom != nil {
+		return custom.ToMiddleware(entry.entryName)
+	}
+
+	return boot.toMiddleware(name, entry.entryName, entry.LoggerEntry, entry.EventEntry, nil), nil
+}
+
+// checkReloadable returns error with keys of config which could not be reloaded but changed.
+//
+// Route groups could not be added, removed or moved, only middlewares of them are compared later.
+func checkReloadable(prev, next *BootGinElement) error {
+	changed := make([]string, 0)
+
+	prevV, nextV := reflect.ValueOf(prev).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < prevV.NumField(); i++ {
+		field := prevV.Type().Field(i)
+		if field.Name == "Middleware" || field.Name == "RouteGroups" {
+			continue
+		}
+
+		if !reflect.DeepEqual(prevV.Field(i).Interface(), nextV.Field(i).Interface()) {
+			changed = append(changed, strings.Split(field.Tag.Get("yaml"), ",")[0])
+		}
+	}
+
+	if len(prev.RouteGroups) != len(next.RouteGroups) {
+		changed = append(changed, "routeGroups")
+	} else {
+		for i := range prev.RouteGroups {
+			if prev.RouteGroups[i].Name != next.RouteGroups[i].Name || prev.RouteGroups[i].Path != next.RouteGroups[i].Path {
+				changed = append(changed, fmt.Sprintf("routeGroups[%d]", i))
+			}
+		}
+	}
+
+	if len(changed) > 0 {
+		return fmt.Errorf("%s could not be reloaded, restart is required", strings.Join(changed, ", "))
+	}
+
+	return nil
+}
+
+// reloadMiddlewareFromFile reads boot config file and reloads middlewares.
+func (entry *GinEntry) reloadMiddlewareFromFile() error {
+	raw, err := os.ReadFile(entry.reloadPath)
+	if err != nil {
+		entry.LoggerEntry.Warn("Error occurs while reading boot config file.",
+			zap.String("entryName", entry.entryName), zap.Error(err))
+		return err
+	}
+
+	return entry.ReloadMiddleware(raw)
+}
+
+// startMiddlewareReloader starts watching SIGHUP and modification of boot config file.
+func (entry *GinEntry) startMiddlewareReloader() {
+	entry.middlewareReloader = newMiddlewareReloader(entry.reloadPath)
+	entry.middlewareReloader.watch(entry.reloadInterval, func() {
+		// error was recorded in event already
+		_ = entry.reloadMiddlewareFromFile()
+	})
+}
+
+// middlewareReloader calls onChange on SIGHUP or if boot config file was modified.
+type middlewareReloader struct {
+	path     string
+	modTime  time.Time
+	sigChan  chan os.Signal
+	quitChan chan struct{}
+	quitOnce sync.Once
+}
+
+// newMiddlewareReloader creates middlewareReloader with boot config file in path.
+func newMiddlewareReloader(path string) *middlewareReloader {
+	reloader := &middlewareReloader{
+		path:     path,
+		sigChan:  make(chan os.Signal, 1),
+		quitChan: make(chan struct{}),
+	}
+	reloader.modTime = reloader.latestModTime()
+
+	return reloader
+}
+
+// watch SIGHUP and check boot config file with interval if interval is positive.
+func (r *middlewareReloader) watch(interval time.Duration, onChange func()) {
+	signal.Notify(r.sigChan, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		tick = ticker.C
+		go func() {
+			<-r.quitChan
+			ticker.Stop()
+		}()
+	}
+
+	go func() {
+		for {
+			select {
+			case <-r.sigChan:
+				r.modTime = r.latestModTime()
+				onChange()
+			case <-tick:
+				if modTime := r.latestModTime(); modTime.After(r.modTime) {
+					r.modTime = modTime
+					onChange()
+				}
+			case <-r.quitChan:
+				return
+			}
+		}
+	}()
+}
+
+// stop watching SIGHUP and boot config file.
+func (r *middlewareReloader) stop() {
+	r.quitOnce.Do(func() {
+		signal.Stop(r.sigChan)
+		close(r.quitChan)
+	})
+}
+
+// latestModTime returns modification time of boot config file, os.Stat follows symlinks like ConfigMap mounts.
+func (r *middlewareReloader) latestModTime() time.Time {
+	if info, err := os.Stat(r.path); err == nil {
+		return info.ModTime()
+	}
+
+	return time.Time{}
+}
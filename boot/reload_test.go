@@ -0,0 +1,185 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const reloadBootTmpl = `
+gin:
+  - name: %s
+    port: 0
+    enabled: true
+    middleware:
+      auth:
+        enabled: true
+        basic: ["%s"]
+        ignore: ["/v1"]
+    routeGroups:
+      - name: v1
+        path: /v1
+        middleware:
+          auth:
+            enabled: true
+            basic: ["%s"]
+`
+
+func TestGinEntry_ReloadMiddleware(t *testing.T) {
+	name := "ut-reload"
+	entry := RegisterGinEntryYAML([]byte(fmt.Sprintf(reloadBootTmpl, name, "user:pass", "group:pass")))[name].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	entry.Router.GET("/ut", func(ctx *gin.Context) {})
+	entry.Group("v1").GET("/ut", func(ctx *gin.Context) {})
+
+	assert.Equal(t, http.StatusOK, reloadRequest(entry, "/ut", "user", "pass"))
+	assert.Equal(t, http.StatusOK, reloadRequest(entry, "/v1/ut", "group", "pass"))
+
+	// only changed middlewares are swapped
+	assert.Nil(t, entry.ReloadMiddleware([]byte(fmt.Sprintf(reloadBootTmpl, name, "user:new", "group:pass"))))
+	assert.Equal(t, http.StatusUnauthorized, reloadRequest(entry, "/ut", "user", "pass"))
+	assert.Equal(t, http.StatusOK, reloadRequest(entry, "/ut", "user", "new"))
+	assert.Equal(t, []string{"user:new"}, entry.bootConfig.Middleware.Auth.Basic)
+
+	reloaded, err := entry.reloadMiddleware([]byte(fmt.Sprintf(reloadBootTmpl, name, "user:new", "group:new")))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"routeGroups[0].middleware.auth"}, reloaded)
+	assert.Equal(t, http.StatusUnauthorized, reloadRequest(entry, "/v1/ut", "group", "pass"))
+	assert.Equal(t, http.StatusOK, reloadRequest(entry, "/v1/ut", "group", "new"))
+
+	// chain is not changed
+	assert.Equal(t, []string{MiddlewarePanic, MiddlewareAuth}, entry.GetMiddlewareChain())
+	assert.Len(t, entry.Router.Handlers, 2)
+}
+
+func TestGinEntry_ReloadMiddleware_Rejected(t *testing.T) {
+	name := "ut-reload-rejected"
+	boot := fmt.Sprintf(reloadBootTmpl, name, "user:pass", "group:pass")
+	entry := RegisterGinEntryYAML([]byte(boot))[name].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	cases := map[string]string{
+		"port could not be reloaded":           strings.Replace(boot, "port: 0", "port: 8096", 1),
+		"tls, listener could not be reloaded":  boot + "    tls:\n      minVersion: \"1.2\"\n    listener:\n      type: unix\n      path: /tmp/ut.sock\n",
+		"routeGroups[0] could not be reloaded": strings.Replace(boot, "path: /v1", "path: /v2", 1),
+		"changed from [panic, auth] to [panic, gzip, auth]": strings.Replace(boot,
+			"    middleware:\n      auth:", "    middleware:\n      gzip:\n        enabled: true\n      auth:", 1),
+		"middleware.errorModel could not be reloaded": strings.Replace(boot,
+			"    middleware:\n      auth:", "    middleware:\n      errorModel: amazon\n      auth:", 1),
+		"not found in boot config": strings.Replace(boot, name, "ut-reload-other", 1),
+		"unknown key":              boot + "    unknown: true\n",
+	}
+
+	for expected, raw := range cases {
+		err := entry.ReloadMiddleware([]byte(raw))
+		if assert.NotNil(t, err, expected) {
+			assert.Contains(t, err.Error(), expected)
+		}
+	}
+
+	// nothing changed
+	entry.Router.GET("/ut", func(ctx *gin.Context) {})
+	assert.Equal(t, http.StatusOK, reloadRequest(entry, "/ut", "user", "pass"))
+
+	// entry registered in code
+	entry = RegisterGinEntry(WithName("ut-reload-code"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.NotNil(t, entry.ReloadMiddleware([]byte(boot)))
+}
+
+func TestGinEntry_ReloadMiddleware_Prom(t *testing.T) {
+	name := "ut-reload-prom"
+	boot := `
+gin:
+  - name: ut-reload-prom
+    port: 0
+    enabled: true
+    middleware:
+      prom:
+        enabled: true
+`
+	entry := RegisterGinEntryYAML([]byte(boot))[name].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	err := entry.ReloadMiddleware([]byte(boot + "        ignore: [\"/ut\"]\n"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "middleware.prom could not be reloaded")
+}
+
+func TestBootMiddlewareReload_ToOptions(t *testing.T) {
+	opts, err := (&BootMiddlewareReload{}).ToOptions()
+	assert.Nil(t, err)
+	assert.Empty(t, opts)
+
+	_, err = (&BootMiddlewareReload{Enabled: true}).ToOptions()
+	assert.NotNil(t, err)
+
+	_, err = (&BootMiddlewareReload{Enabled: true, Path: "boot.yaml", IntervalMs: -1}).ToOptions()
+	assert.NotNil(t, err)
+
+	opts, err = (&BootMiddlewareReload{Enabled: true, Path: "boot.yaml", IntervalMs: 10}).ToOptions()
+	assert.Nil(t, err)
+	entry := RegisterGinEntry(append(opts, WithName("ut-reload-opts"))...)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.True(t, entry.IsMiddlewareReloadEnabled())
+	assert.Equal(t, 10*time.Millisecond, entry.reloadInterval)
+}
+
+func TestGinEntry_MiddlewareReloader(t *testing.T) {
+	name := "ut-reload-file"
+	path := filepath.Join(t.TempDir(), "boot.yaml")
+	tmpl := reloadBootTmpl + "    middlewareReload:\n      enabled: true\n      path: " + path + "\n      intervalMs: 10\n"
+
+	boot := fmt.Sprintf(tmpl, name, "user:pass", "group:pass")
+	assert.Nil(t, os.WriteFile(path, []byte(boot), 0644))
+
+	entry := RegisterGinEntryYAML([]byte(boot))[name].(*GinEntry)
+	entry.Router.GET("/ut", func(ctx *gin.Context) {})
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	defer entry.Interrupt(context.TODO())
+
+	// modification of file
+	assert.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf(tmpl, name, "user:new", "group:pass")), 0644))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(path, future, future))
+	assert.Eventually(t, func() bool {
+		return reloadRequest(entry, "/ut", "user", "new") == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// SIGHUP
+	assert.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf(tmpl, name, "user:hup", "group:pass")), 0644))
+	assert.Nil(t, os.Chtimes(path, future, future))
+	entry.middlewareReloader.sigChan <- syscall.SIGHUP
+	assert.Eventually(t, func() bool {
+		return reloadRequest(entry, "/ut", "user", "hup") == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func reloadRequest(entry *GinEntry, path, user, pass string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.SetBasicAuth(user, pass)
+	entry.Router.ServeHTTP(w, req)
+
+	return w.Code
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// envInheritedListeners comma separated <listener name>=<fd> pairs passed to child process
+	envInheritedListeners = "RK_GIN_INHERITED_LISTENERS"
+	// envReadyFd fd of pipe which child process would write to once inherited listeners are served
+	envReadyFd = "RK_GIN_READY_FD"
+	// defaultRestartReadyTimeout max duration of waiting child process to be ready
+	defaultRestartReadyTimeout = 30 * time.Second
+)
+
+// BootGracefulRestart bootstrap config of graceful restart.
+//
+// Once enabled, process would re-exec itself on SIGUSR2 and pass listening sockets to child process.
+// After child process served all of inherited listeners, shutdown signal would be sent to
+// rkentry.GlobalAppCtx, so that WaitForShutdownSig returns and entries drain in-flight requests as usual.
+type BootGracefulRestart struct {
+	Enabled        bool `yaml:"enabled" json:"enabled"`
+	ReadyTimeoutMs int  `yaml:"readyTimeoutMs" json:"readyTimeoutMs"`
+}
+
+// inheritance holds listeners passed by parent process.
+type inheritance struct {
+	lock    sync.Mutex
+	once    sync.Once
+	fds     map[string]int
+	served  map[string]bool
+	readyFd int
+}
+
+var inherited = &inheritance{}
+
+// parse reads inherited listeners from environment variables once.
+func (i *inheritance) parse() {
+	i.once.Do(func() {
+		i.fds = parseInheritedListeners(os.Getenv(envInheritedListeners))
+		i.served = make(map[string]bool)
+		i.readyFd = -1
+		if fd, err := strconv.Atoi(os.Getenv(envReadyFd)); err == nil {
+			i.readyFd = fd
+		}
+	})
+}
+
+// listener returns listener with name passed by parent process, false would be returned if missing.
+func (i *inheritance) listener(name string) (net.Listener, bool, error) {
+	i.parse()
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	fd, ok := i.fds[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	ln, err := listenFd(fd)
+	return ln, true, err
+}
+
+// markServed records listener as served, parent process would be notified once all inherited listeners are served.
+func (i *inheritance) markServed(names ...string) {
+	i.parse()
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, name := range names {
+		if _, ok := i.fds[name]; ok {
+			i.served[name] = true
+		}
+	}
+
+	if i.readyFd < 0 || len(i.served) < len(i.fds) {
+		return
+	}
+
+	if f := os.NewFile(uintptr(i.readyFd), "ready-pipe"); f != nil {
+		f.Write([]byte{1})
+		f.Close()
+	}
+	i.readyFd = -1
+}
+
+// parseInheritedListeners parses <listener name>=<fd> pairs.
+func parseInheritedListeners(str string) map[string]int {
+	res := make(map[string]int)
+
+	for _, pair := range strings.Split(str, ",") {
+		tokens := strings.SplitN(pair, "=", 2)
+		if len(tokens) != 2 {
+			continue
+		}
+
+		if fd, err := strconv.Atoi(tokens[1]); err == nil {
+			res[tokens[0]] = fd
+		}
+	}
+
+	return res
+}
+
+// formatInheritedListeners formats <listener name>=<fd> pairs in order of name.
+func formatInheritedListeners(fds map[string]int) string {
+	pairs := make([]string, 0, len(fds))
+	for name, fd := range fds {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", name, fd))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// listenerName returns name of main listener which is used while passing to child process.
+func (entry *GinEntry) listenerName() string {
+	return entry.entryName
+}
+
+// managementListenerName returns name of management listener which is used while passing to child process.
+func (entry *GinEntry) managementListenerName() string {
+	return entry.entryName + "/management"
+}
+
+// IsGracefulRestartEnabled Is graceful restart enabled?
+func (entry *GinEntry) IsGracefulRestartEnabled() bool {
+	return entry.gracefulRestart
+}
+
+// WithGracefulRestart enable graceful restart with max duration of waiting child process to be ready.
+func WithGracefulRestart(readyTimeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.gracefulRestart = true
+		entry.restartReadyTimeout = defaultRestartReadyTimeout
+		if readyTimeout > 0 {
+			entry.restartReadyTimeout = readyTimeout
+		}
+	}
+}
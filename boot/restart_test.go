@@ -0,0 +1,156 @@
+//go:build !race && !windows
+// +build !race,!windows
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const envRestartChild = "RK_GIN_UT_RESTART_CHILD"
+
+func TestInheritedListeners(t *testing.T) {
+	fds := map[string]int{"ut-b": 4, "ut-a": 3, "ut-a/management": 5}
+	str := formatInheritedListeners(fds)
+	assert.Equal(t, "ut-a/management=5,ut-a=3,ut-b=4", str)
+	assert.Equal(t, fds, parseInheritedListeners(str))
+
+	assert.Empty(t, parseInheritedListeners(""))
+	assert.Empty(t, parseInheritedListeners("ut-a,ut-b=x"))
+}
+
+func TestFilterEnv(t *testing.T) {
+	env := []string{"A=1", envReadyFd + "=4", "B=2", envInheritedListeners + "=ut=3"}
+	assert.Equal(t, []string{"A=1", "B=2"}, filterEnv(env, envInheritedListeners, envReadyFd))
+}
+
+func TestRegisterGinEntryYAML_WithGracefulRestart(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-restart-yaml
+    port: 1949
+    enabled: true
+    gracefulRestart:
+      enabled: true
+      readyTimeoutMs: 1000
+`
+	entries := RegisterGinEntryYAML([]byte(bootStr))
+	entry := entries["ut-restart-yaml"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.True(t, entry.IsGracefulRestartEnabled())
+	assert.Equal(t, time.Second, entry.restartReadyTimeout)
+}
+
+func TestGracefulRestart_WithoutEntry(t *testing.T) {
+	assert.NotNil(t, GracefulRestart())
+}
+
+func TestGinEntry_GracefulRestart(t *testing.T) {
+	if os.Getenv(envRestartChild) == "true" {
+		t.Skip("running as child process")
+	}
+
+	entry := RegisterGinEntry(
+		WithName("ut-restart"),
+		WithHost("127.0.0.1"),
+		WithPort(0),
+		WithGracefulRestart(10*time.Second))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "parent")
+	})
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	url := "http://" + entry.ListenAddr().String() + "/ut"
+	assert.Equal(t, "parent", restartResponse(t, url))
+
+	defer func() {
+		gracefulRestarter.command = selfCommand
+		atomic.StoreInt32(&gracefulRestarter.restarting, 0)
+	}()
+
+	// child process exits before ready, parent keeps serving
+	gracefulRestarter.command = func() (*exec.Cmd, error) {
+		return exec.Command(os.Args[0], "-test.run=^$"), nil
+	}
+	_, err := gracefulRestarter.restart()
+	assert.NotNil(t, err)
+	assert.Equal(t, "parent", restartResponse(t, url))
+
+	// child process inherits listener
+	gracefulRestarter.command = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestGinEntry_GracefulRestartChild$")
+		cmd.Env = append(os.Environ(), envRestartChild+"=true")
+		return cmd, nil
+	}
+	proc, err := gracefulRestarter.restart()
+	assert.Nil(t, err)
+	if proc == nil {
+		entry.Interrupt(context.TODO())
+		return
+	}
+	defer func() {
+		proc.Kill()
+		proc.Wait()
+	}()
+
+	// shutdown signal would be sent to GlobalAppCtx
+	select {
+	case <-rkentry.GlobalAppCtx.GetShutdownSig():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "shutdown signal was not sent")
+	}
+	entry.Interrupt(context.TODO())
+
+	// listener is served by child process after parent stopped
+	assert.Equal(t, "child", restartResponse(t, url))
+}
+
+// TestGinEntry_GracefulRestartChild is executed by TestGinEntry_GracefulRestart as child process.
+func TestGinEntry_GracefulRestartChild(t *testing.T) {
+	if os.Getenv(envRestartChild) != "true" {
+		t.Skip("only runs as child process")
+	}
+
+	entry := RegisterGinEntry(
+		WithName("ut-restart"),
+		WithPort(0),
+		WithGracefulRestart(0))
+	entry.Router.GET("/ut", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "child")
+	})
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+	// killed by parent process
+	time.Sleep(10 * time.Second)
+}
+
+func restartResponse(t *testing.T, url string) string {
+	client := &http.Client{
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+
+	resp, err := client.Get(url)
+	assert.Nil(t, err)
+	if resp == nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	bytes, _ := io.ReadAll(resp.Body)
+	return string(bytes)
+}
@@ -0,0 +1,283 @@
+//go:build !windows
+// +build !windows
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"go.uber.org/zap"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// restarter passes listeners of GinEntry to re-exec'd child process.
+type restarter struct {
+	lock       sync.Mutex
+	entries    map[string]*GinEntry
+	signalOnce sync.Once
+	restarting int32
+	command    func() (*exec.Cmd, error)
+}
+
+var gracefulRestarter = &restarter{
+	entries: make(map[string]*GinEntry),
+	command: selfCommand,
+}
+
+// GracefulRestart passes listening sockets of GinEntry with graceful restart enabled to a re-exec'd child process,
+// waits for child process to be ready and sends shutdown signal to rkentry.GlobalAppCtx.
+//
+// It is triggered by SIGUSR2 automatically.
+func GracefulRestart() error {
+	_, err := gracefulRestarter.restart()
+	return err
+}
+
+// add registers GinEntry and starts watching SIGUSR2.
+func (r *restarter) add(entry *GinEntry) {
+	r.lock.Lock()
+	r.entries[entry.entryName] = entry
+	r.lock.Unlock()
+
+	r.signalOnce.Do(func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGUSR2)
+
+		go func() {
+			for range sigChan {
+				// error was recorded in event already
+				_, _ = r.restart()
+			}
+		}()
+	})
+}
+
+// remove unregisters GinEntry.
+func (r *restarter) remove(entry *GinEntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.entries[entry.entryName] == entry {
+		delete(r.entries, entry.entryName)
+	}
+}
+
+// restart hands off listeners and returns child process once it is ready.
+func (r *restarter) restart() (*os.Process, error) {
+	if !atomic.CompareAndSwapInt32(&r.restarting, 0, 1) {
+		return nil, errors.New("graceful restart is in progress")
+	}
+
+	r.lock.Lock()
+	entries := make([]*GinEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	r.lock.Unlock()
+
+	if len(entries) < 1 {
+		atomic.StoreInt32(&r.restarting, 0)
+		return nil, errors.New("no GinEntry with graceful restart enabled")
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].entryName < entries[j].entryName
+	})
+
+	event, logger := entries[0].logBasicInfo("GracefulRestart", context.Background())
+
+	proc, err := r.handoff(entries)
+	if err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while handing off listeners to child process.", event.ListPayloads()...)
+		entries[0].EventEntry.FinishWithCond(event, false)
+		atomic.StoreInt32(&r.restarting, 0)
+		return nil, err
+	}
+
+	// child process owns unix socket file from now on
+	for _, entry := range entries {
+		for _, ln := range []net.Listener{entry.listener, entry.managementListener} {
+			if unixLn, ok := ln.(*net.UnixListener); ok {
+				unixLn.SetUnlinkOnClose(false)
+			}
+		}
+	}
+
+	event.AddPayloads(zap.Int("childPid", proc.Pid))
+	logger.Info("Child process is ready, shutting down.", zap.Int("childPid", proc.Pid))
+	entries[0].EventEntry.Finish(event)
+
+	// WaitForShutdownSig() returns and entries would be interrupted as usual
+	go func() {
+		rkentry.GlobalAppCtx.GetShutdownSig() <- syscall.SIGTERM
+	}()
+
+	return proc, nil
+}
+
+// handoff starts child process with listening sockets and waits for it to be ready.
+func (r *restarter) handoff(entries []*GinEntry) (*os.Process, error) {
+	files := make([]*os.File, 0)
+	defer func() {
+		for i := range files {
+			files[i].Close()
+		}
+	}()
+
+	fds := make(map[string]int)
+	listeners := make([]net.Listener, 0)
+	timeout := time.Duration(0)
+	for _, entry := range entries {
+		if entry.restartReadyTimeout > timeout {
+			timeout = entry.restartReadyTimeout
+		}
+
+		named := map[string]net.Listener{
+			entry.listenerName():           entry.listener,
+			entry.managementListenerName(): entry.managementListener,
+		}
+		for name, ln := range named {
+			if ln == nil {
+				continue
+			}
+
+			f, err := listenerFile(ln)
+			if err != nil {
+				return nil, err
+			}
+			// ExtraFiles starts from fd 3 in child process
+			fds[name] = 3 + len(files)
+			files = append(files, f)
+			listeners = append(listeners, ln)
+		}
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer readyReader.Close()
+
+	cmd, err := r.command()
+	if err != nil {
+		readyWriter.Close()
+		return nil, err
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(filterEnv(cmd.Env, envInheritedListeners, envReadyFd),
+		envInheritedListeners+"="+formatInheritedListeners(fds),
+		envReadyFd+"="+strconv.Itoa(3+len(files)))
+	cmd.ExtraFiles = append(files, readyWriter)
+
+	err = cmd.Start()
+	// close write end in parent, so that read returns EOF if child process exits before ready
+	readyWriter.Close()
+	// os/exec puts shared file description into blocking mode, which would block Accept() of parent
+	for i := range listeners {
+		setNonblock(listeners[i])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	readyChan := make(chan error, 1)
+	go func() {
+		_, err := readyReader.Read(make([]byte, 1))
+		readyChan <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-readyChan:
+		if err != nil {
+			err = fmt.Errorf("child process exited before ready, %v", err)
+		}
+	case <-timer.C:
+		err = fmt.Errorf("child process is not ready within %s", timeout)
+	}
+
+	if err != nil {
+		cmd.Process.Kill()
+		go cmd.Wait()
+		return nil, err
+	}
+
+	return cmd.Process, nil
+}
+
+// selfCommand re-executes current binary with the same arguments.
+func selfCommand() (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd, nil
+}
+
+// listenerFile returns duplicated file of listener.
+func listenerFile(ln net.Listener) (*os.File, error) {
+	filer, ok := ln.(interface {
+		File() (*os.File, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("listener %T could not be passed to child process", ln)
+	}
+
+	return filer.File()
+}
+
+// setNonblock puts listener back into non-blocking mode.
+func setNonblock(ln net.Listener) {
+	if conn, ok := ln.(syscall.Conn); ok {
+		if raw, err := conn.SyscallConn(); err == nil {
+			raw.Control(func(fd uintptr) {
+				syscall.SetNonblock(int(fd), true)
+			})
+		}
+	}
+}
+
+// filterEnv removes environment variables with keys.
+func filterEnv(env []string, keys ...string) []string {
+	res := make([]string, 0, len(env))
+
+loop:
+	for _, kv := range env {
+		for _, key := range keys {
+			if strings.HasPrefix(kv, key+"=") {
+				continue loop
+			}
+		}
+		res = append(res, kv)
+	}
+
+	return res
+}
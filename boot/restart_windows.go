@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import "errors"
+
+// restarter is not supported on windows since sockets could not be passed to child process with os/exec.
+type restarter struct{}
+
+var gracefulRestarter = &restarter{}
+
+// GracefulRestart is not supported on windows.
+func GracefulRestart() error {
+	return errors.New("graceful restart is not supported on windows")
+}
+
+// add does nothing on windows.
+func (r *restarter) add(*GinEntry) {}
+
+// remove does nothing on windows.
+func (r *restarter) remove(*GinEntry) {}
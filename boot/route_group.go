@@ -0,0 +1,106 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"strings"
+)
+
+// BootRouteGroup bootstrap config of route group with dedicated middlewares.
+//
+// Middlewares of group would be added after middlewares of entry. Middleware has the same schema as middleware of
+// entry except that prom and errorModel are only supported at entry level, and panic middleware of entry is reused.
+// Paths in ignore of group are only skipped by middlewares of group.
+type BootRouteGroup struct {
+	Name       string         `yaml:"name" json:"name"`
+	Path       string         `yaml:"path" json:"path"`
+	Middleware BootMiddleware `yaml:"middleware" json:"middleware"`
+}
+
+// ToMiddlewares validates route group and creates enabled middlewares of group in resolved order.
+func (boot *BootRouteGroup) ToMiddlewares(entryName string,
+	loggerEntry *rkentry.LoggerEntry,
+	eventEntry *rkentry.EventEntry) ([]string, []gin.HandlerFunc, error) {
+	if len(strings.TrimSpace(boot.Name)) < 1 {
+		return nil, nil, errors.New("empty name of route group")
+	}
+
+	if !strings.HasPrefix(boot.Path, "/") {
+		return nil, nil, fmt.Errorf("path of route group %q must start with /", boot.Name)
+	}
+
+	if boot.Middleware.Prom.Enabled {
+		return nil, nil, fmt.Errorf("prom middleware is not supported in route group %q", boot.Name)
+	}
+
+	if len(boot.Middleware.ErrorModel) > 0 {
+		return nil, nil, fmt.Errorf("errorModel is not supported in route group %q", boot.Name)
+	}
+
+	return boot.Middleware.toMiddlewares(entryName, loggerEntry, eventEntry, nil, false)
+}
+
+// routeGroup is gin.RouterGroup with name and names of middlewares.
+type routeGroup struct {
+	name  string
+	path  string
+	chain []string
+	slots map[string]*middlewareSlot
+	group *gin.RouterGroup
+}
+
+// Group returns route group declared in routeGroups of boot YAML, nil if not found.
+//
+// Handlers registered in route group would go through middlewares of entry and then middlewares of group.
+// If cors middleware is enabled in group, OPTIONS requests under path of group are served by the group already,
+// so that preflight requests of browsers go through cors middleware.
+func (entry *GinEntry) Group(name string) *gin.RouterGroup {
+	for i := range entry.routeGroups {
+		if entry.routeGroups[i].name == name {
+			return entry.routeGroups[i].group
+		}
+	}
+
+	return nil
+}
+
+// addRouteGroup creates route group with named middlewares.
+func (entry *GinEntry) addRouteGroup(name, path string, names []string, mids []gin.HandlerFunc) error {
+	if entry.Group(name) != nil {
+		return fmt.Errorf("duplicate route group %q", name)
+	}
+
+	// middlewares are served behind slots, so that ReloadMiddleware could swap them
+	slots := make(map[string]*middlewareSlot)
+	handlers := make([]gin.HandlerFunc, 0, len(mids))
+	for i := range mids {
+		slot := newMiddlewareSlot(mids[i])
+		slots[names[i]] = slot
+		handlers = append(handlers, slot.handle)
+	}
+
+	group := entry.Router.Group(path, handlers...)
+
+	// gin runs middlewares of group only for matched routes, preflight requests are routed to group explicitly,
+	// cors middleware aborts them, and the others fall through to 404
+	if _, ok := slots[MiddlewareCors]; ok {
+		group.OPTIONS("/*any", noRoute)
+	}
+
+	entry.routeGroups = append(entry.routeGroups, &routeGroup{
+		name:  name,
+		path:  path,
+		chain: append([]string{}, names...),
+		slots: slots,
+		group: group,
+	})
+
+	return nil
+}
@@ -0,0 +1,139 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBootRouteGroup_ToMiddlewares(t *testing.T) {
+	// without name
+	_, _, err := (&BootRouteGroup{Path: "/ut"}).ToMiddlewares("ut-entry", nil, nil)
+	assert.NotNil(t, err)
+
+	// invalid path
+	_, _, err = (&BootRouteGroup{Name: "ut-group", Path: "ut"}).ToMiddlewares("ut-entry", nil, nil)
+	assert.NotNil(t, err)
+
+	// with prom
+	group := &BootRouteGroup{Name: "ut-group", Path: "/ut"}
+	group.Middleware.Prom.Enabled = true
+	_, _, err = group.ToMiddlewares("ut-entry", nil, nil)
+	assert.NotNil(t, err)
+
+	// with error model
+	group = &BootRouteGroup{Name: "ut-group", Path: "/ut"}
+	group.Middleware.ErrorModel = "amazon"
+	_, _, err = group.ToMiddlewares("ut-entry", nil, nil)
+	assert.NotNil(t, err)
+
+	// happy case, panic middleware is skipped
+	group = &BootRouteGroup{Name: "ut-group", Path: "/ut"}
+	group.Middleware.Meta.Enabled = true
+	names, mids, err := group.ToMiddlewares("ut-entry", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{MiddlewareMeta}, names)
+	assert.Len(t, mids, 1)
+}
+
+func TestRegisterGinEntryYAML_WithRouteGroups(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-route-groups
+    port: 1949
+    enabled: true
+    middleware:
+      meta:
+        enabled: true
+    routeGroups:
+      - name: admin
+        path: /api/admin
+        middleware:
+          order: ["ratelimit", "auth"]
+          auth:
+            enabled: true
+            basic: ["user:pass"]
+          ratelimit:
+            enabled: true
+      - name: public
+        path: /api/public
+        middleware:
+          cors:
+            enabled: true
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-route-groups"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.Nil(t, entry.Group("unknown"))
+	assert.NotNil(t, entry.Group("admin"))
+	assert.Equal(t, "/api/admin", entry.Group("admin").BasePath())
+	assert.Contains(t, entry.String(),
+		`"routeGroups":[{"middlewareChain":["rateLimit","auth"],"name":"admin","path":"/api/admin"},`+
+			`{"middlewareChain":["cors"],"name":"public","path":"/api/public"}]`)
+
+	handler := func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	}
+	entry.Group("admin").GET("/ut", handler)
+	entry.Group("public").GET("/ut", handler)
+
+	// admin requires basic auth
+	writer := httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/api/admin/ut", nil))
+	assert.Equal(t, http.StatusUnauthorized, writer.Code)
+	assert.NotEmpty(t, writer.Header().Get("X-Request-Id"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/ut", nil)
+	req.SetBasicAuth("user", "pass")
+	writer = httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, req)
+	assert.Equal(t, http.StatusOK, writer.Code)
+
+	// public does not
+	writer = httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/api/public/ut", nil))
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.NotEmpty(t, writer.Header().Get("X-Request-Id"))
+
+	// preflight of public goes through cors middleware of group
+	req = httptest.NewRequest(http.MethodOptions, "/api/public/ut", nil)
+	req.Header.Set("Origin", "http://localhost:8080")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	writer = httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, req)
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.Equal(t, "http://localhost:8080", writer.Header().Get("Access-Control-Allow-Origin"))
+	assert.NotEmpty(t, writer.Header().Get("Access-Control-Allow-Methods"))
+
+	// admin has no cors middleware
+	req.URL.Path = "/api/admin/ut"
+	writer = httptest.NewRecorder()
+	entry.Router.ServeHTTP(writer, req)
+	assert.Empty(t, writer.Header().Get("Access-Control-Allow-Origin"))
+
+	// duplicate group
+	bootStr = `
+gin:
+  - name: ut-route-groups-duplicate
+    port: 1949
+    enabled: true
+    routeGroups:
+      - name: admin
+        path: /api/admin
+      - name: admin
+        path: /api/admin2
+`
+	defer assertPanic(t)
+	RegisterGinEntryYAML([]byte(bootStr))
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ValidateMode checks mode of gin, empty mode is allowed which means GIN_MODE environment variable or release mode.
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		return nil
+	}
+
+	return fmt.Errorf("unknown mode %q, options: %s", mode,
+		strings.Join([]string{gin.DebugMode, gin.ReleaseMode, gin.TestMode}, ", "))
+}
+
+// resolveMode returns mode of gin, GIN_MODE environment variable is honoured if mode of entry is empty,
+// release mode would be used if both of them are missing.
+func (entry *GinEntry) resolveMode() string {
+	if len(entry.mode) > 0 {
+		return entry.mode
+	}
+
+	if mode := os.Getenv(gin.EnvGinMode); ValidateMode(mode) == nil && len(mode) > 0 {
+		return mode
+	}
+
+	return gin.ReleaseMode
+}
+
+// configureRouter applies routing options to Router and renders 404 and 405 with error builder of entry.
+func (entry *GinEntry) configureRouter() {
+	entry.Router.HandleMethodNotAllowed = entry.handleMethodNotAllowed
+	entry.Router.RedirectTrailingSlash = entry.redirectTrailingSlash
+	entry.Router.RedirectFixedPath = entry.redirectFixedPath
+	entry.Router.RemoveExtraSlash = entry.removeExtraSlash
+
+	entry.Router.NoRoute(noRoute)
+	entry.Router.NoMethod(noMethod)
+}
+
+// noRoute renders 404 with error builder in gin.Context.
+func noRoute(ctx *gin.Context) {
+	abortWithRouteError(ctx, http.StatusNotFound, fmt.Sprintf("Path %s not found", ctx.Request.URL.Path))
+}
+
+// noMethod renders 405 with error builder in gin.Context.
+func noMethod(ctx *gin.Context) {
+	abortWithRouteError(ctx, http.StatusMethodNotAllowed,
+		fmt.Sprintf("Method %s is not allowed on path %s", ctx.Request.Method, ctx.Request.URL.Path))
+}
+
+// abortWithRouteError aborts request with error model of entry.
+//
+// Request id is attached as detail if error model could not carry request scoped values by itself.
+func abortWithRouteError(ctx *gin.Context, code int, msg string) {
+	builder := rkginctx.GetErrorBuilder(ctx)
+
+	err := builder.New(code, msg)
+	if _, ok := err.(rkginctx.ErrorWithRequestScope); !ok {
+		if requestId := rkginctx.GetRequestId(ctx); len(requestId) > 0 {
+			err = builder.New(code, msg, map[string]string{"requestId": requestId})
+		}
+	}
+
+	rkginctx.AbortWithError(ctx, err)
+}
+
+// WithMode provide mode of gin, one of debug, release and test.
+//
+// Mode of gin is process wide, mode of the entry registered last wins.
+func WithMode(mode string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.mode = mode
+	}
+}
+
+// WithHandleMethodNotAllowed respond 405 instead of 404 if path matches route of another method.
+func WithHandleMethodNotAllowed(enabled bool) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.handleMethodNotAllowed = enabled
+	}
+}
+
+// WithRedirectTrailingSlash redirect /foo/ to /foo if only the latter exists, or vice versa, enabled by default.
+func WithRedirectTrailingSlash(enabled bool) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.redirectTrailingSlash = enabled
+	}
+}
+
+// WithRedirectFixedPath redirect path with case-insensitive match and superfluous elements like ../ removed.
+func WithRedirectFixedPath(enabled bool) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.redirectFixedPath = enabled
+	}
+}
+
+// WithRemoveExtraSlash route path with extra slashes removed, like //foo to /foo.
+func WithRemoveExtraSlash(enabled bool) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.removeExtraSlash = enabled
+	}
+}
@@ -0,0 +1,153 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestValidateMode(t *testing.T) {
+	assert.Nil(t, ValidateMode(""))
+	assert.Nil(t, ValidateMode(gin.DebugMode))
+	assert.Nil(t, ValidateMode(gin.ReleaseMode))
+	assert.Nil(t, ValidateMode(gin.TestMode))
+	assert.NotNil(t, ValidateMode("invalid"))
+}
+
+func TestGinEntry_ResolveMode(t *testing.T) {
+	defer gin.SetMode(gin.ReleaseMode)
+
+	entry := RegisterGinEntry(WithName("ut-router-mode"), WithMode(gin.TestMode))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, gin.TestMode, gin.Mode())
+
+	// GIN_MODE is honoured if mode is missing
+	os.Setenv(gin.EnvGinMode, gin.DebugMode)
+	defer os.Unsetenv(gin.EnvGinMode)
+	entry = RegisterGinEntry(WithName("ut-router-mode-env"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, gin.DebugMode, gin.Mode())
+
+	os.Unsetenv(gin.EnvGinMode)
+	assert.Equal(t, gin.ReleaseMode, entry.resolveMode())
+}
+
+func TestRegisterGinEntryYAML_WithRouterOptions(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-router-options
+    port: 1949
+    enabled: true
+    mode: release
+    handleMethodNotAllowed: true
+    redirectTrailingSlash: false
+    redirectFixedPath: true
+    removeExtraSlash: true
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-router-options"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.True(t, entry.Router.HandleMethodNotAllowed)
+	assert.False(t, entry.Router.RedirectTrailingSlash)
+	assert.True(t, entry.Router.RedirectFixedPath)
+	assert.True(t, entry.Router.RemoveExtraSlash)
+
+	// defaults of gin
+	entry = RegisterGinEntry(WithName("ut-router-options-default"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.False(t, entry.Router.HandleMethodNotAllowed)
+	assert.True(t, entry.Router.RedirectTrailingSlash)
+	assert.False(t, entry.Router.RedirectFixedPath)
+	assert.False(t, entry.Router.RemoveExtraSlash)
+}
+
+func TestGinEntry_NoRouteWithErrorModel(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-router-rfc7807
+    port: 1949
+    enabled: true
+    handleMethodNotAllowed: true
+    middleware:
+      errorModel: rfc7807
+      meta:
+        enabled: true
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-router-rfc7807"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	entry.Router.GET("/ut", func(ctx *gin.Context) {})
+
+	// 404
+	w := routerRequest(entry.Router, http.MethodGet, "/ut-missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentTypeProblemJson, w.Header().Get(rkmid.HeaderContentType))
+
+	resp := &ErrorProblem{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(t, http.StatusNotFound, resp.Status)
+	assert.Equal(t, "/ut-missing", resp.Instance)
+	assert.NotEmpty(t, resp.RequestId)
+	assert.Equal(t, w.Header().Get(rkmid.HeaderRequestId), resp.RequestId)
+
+	// 405
+	w = routerRequest(entry.Router, http.MethodPost, "/ut")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	resp = &ErrorProblem{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Status)
+	assert.NotEmpty(t, resp.RequestId)
+}
+
+func TestGinEntry_NoRouteWithGoogleModel(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-router-google
+    port: 1949
+    enabled: true
+    middleware:
+      errorModel: google
+      meta:
+        enabled: true
+`
+	entry := RegisterGinEntryYAML([]byte(bootStr))["ut-router-google"].(*GinEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	entry.Router.GET("/ut", func(ctx *gin.Context) {})
+
+	// 404 instead of 405 since handleMethodNotAllowed is disabled
+	w := routerRequest(entry.Router, http.MethodPost, "/ut")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// request id is attached as detail
+	assert.Contains(t, w.Body.String(), `"requestId":"`+w.Header().Get(rkmid.HeaderRequestId)+`"`)
+
+	// without meta middleware
+	entry = RegisterGinEntry(WithName("ut-router-no-meta"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	w = routerRequest(entry.Router, http.MethodGet, "/ut-missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Path /ut-missing not found")
+	assert.NotContains(t, w.Body.String(), "requestId")
+}
+
+func routerRequest(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	router.ServeHTTP(w, req)
+
+	return w
+}
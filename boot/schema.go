@@ -0,0 +1,337 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// JSONSchemaDraft07 version of JSON Schema generated by NewBootGinSchema
+	JSONSchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+)
+
+// JSONSchema is the subset of JSON Schema draft-07 used to describe boot config.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Description          string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	PatternProperties    map[string]*JSONSchema `json:"patternProperties,omitempty" yaml:"patternProperties,omitempty"`
+	Required             []string               `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Examples             []interface{}          `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Default              interface{}            `json:"default,omitempty" yaml:"default,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+}
+
+// schemaDoc is description, default value and options of a key in boot config.
+type schemaDoc struct {
+	description  string
+	defaultValue interface{}
+	enum         []interface{}
+	examples     []interface{}
+	required     []string
+	// enum is matched case-insensitively as boot config is parsed
+	foldEnum bool
+}
+
+// schemaDocs are keyed with YAML path relative to gin element, indexes of list are omitted.
+//
+// Keys of route groups share docs of middleware.
+var schemaDocs = map[string]schemaDoc{
+	"name":                                 {description: "name of entry, must be unique"},
+	"port":                                 {description: "0 means ephemeral port, use GinEntry.ListenAddr() to get bound address"},
+	"enabled":                              {description: "enable entry"},
+	"description":                          {description: "description of entry", defaultValue: ""},
+	"certEntry":                            {description: "reference of cert entry declared above", defaultValue: ""},
+	"loggerEntry":                          {description: "reference of logger entry declared above, STDOUT will be used if missing", defaultValue: ""},
+	"eventEntry":                           {description: "reference of event entry declared above, STDOUT will be used if missing", defaultValue: ""},
+	"sw":                                   {description: "swagger UI"},
+	"sw.path":                              {defaultValue: "sw"},
+	"sw.jsonPaths":                         {description: "path of swagger JSON files"},
+	"sw.headers":                           {defaultValue: []interface{}{}},
+	"docs":                                 {description: "docs UI"},
+	"docs.path":                            {defaultValue: "docs"},
+	"docs.specPaths":                       {description: "path of API spec files"},
+	"docs.headers":                         {defaultValue: []interface{}{}},
+	"docs.style.theme":                     {defaultValue: "light"},
+	"docs.debug":                           {defaultValue: false},
+	"commonService":                        {description: "common service APIs like healthy, ready and alive"},
+	"commonService.pathPrefix":             {defaultValue: "/rk/v1/"},
+	"static":                               {description: "static file handler"},
+	"static.path":                          {defaultValue: "/static"},
+	"static.sourceType":                    {description: "embed.FS can be used either, need to specify in code", enum: []interface{}{"local"}},
+	"static.sourcePath":                    {description: "full path of source directory"},
+	"pprof":                                {description: "pprof handlers"},
+	"pprof.path":                           {defaultValue: "/pprof"},
+	"trustedProxies":                       {description: "IPs or CIDRs of trusted proxies, all proxies are trusted if missing, [] means none"},
+	"mode":                                 {description: "mode of gin, GIN_MODE environment variable or release would be used if missing", enum: []interface{}{"debug", "release", "test"}},
+	"handleMethodNotAllowed":               {description: "respond 405 instead of 404 if path matches route of another method", defaultValue: false},
+	"redirectTrailingSlash":                {description: "redirect /foo/ to /foo if only the latter exists, or vice versa", defaultValue: true},
+	"redirectFixedPath":                    {description: "redirect to path matched case-insensitively after cleaning it", defaultValue: false},
+	"removeExtraSlash":                     {description: "route path with extra slashes removed, like //foo to /foo", defaultValue: false},
+	"hosts":                                {description: "host names served by entry, like api.example.com, *.example.com or *, entries with hosts could share the same port"},
+	"trustedPlatform":                      {description: "platform name or header which contains client IP", defaultValue: "", examples: []interface{}{TrustedPlatformCloudflare, TrustedPlatformGoogleAppEngine}},
+	"remoteIPHeaders":                      {defaultValue: []interface{}{"X-Forwarded-For", "X-Real-IP"}},
+	"server":                               {description: "options of http.Server"},
+	"server.host":                          {description: "address server would bind to", defaultValue: "0.0.0.0"},
+	"server.readTimeoutMs":                 {description: "0 means no timeout", defaultValue: 0},
+	"server.readHeaderTimeoutMs":           {description: "0 means readTimeoutMs", defaultValue: 0},
+	"server.writeTimeoutMs":                {description: "0 means no timeout", defaultValue: 0},
+	"server.idleTimeoutMs":                 {description: "0 means readTimeoutMs", defaultValue: 0},
+	"server.maxHeaderBytes":                {description: "0 means http.DefaultMaxHeaderBytes", defaultValue: 0},
+	"tls":                                  {description: "TLS options, enabled if certEntry provided"},
+	"tls.clientAuth":                       {defaultValue: ClientAuthNone, enum: []interface{}{ClientAuthNone, ClientAuthRequest, ClientAuthRequire, ClientAuthVerify, ClientAuthVerifyIfGiven}, foldEnum: true},
+	"tls.minVersion":                       {description: "empty means default of crypto/tls", defaultValue: "", examples: []interface{}{"1.0", "1.1", "1.2", "1.3"}},
+	"tls.cipherSuites":                     {description: "names defined in crypto/tls", defaultValue: []interface{}{}},
+	"tls.nextProtos":                       {description: "ALPN protocols", defaultValue: []interface{}{}},
+	"tls.reload.enabled":                   {description: "reload certificate if PEM files of certEntry or management.certEntry changed", defaultValue: false},
+	"tls.reload.intervalMs":                {defaultValue: 10000},
+	"listener.type":                        {defaultValue: ListenerTypeTcp, enum: []interface{}{ListenerTypeTcp, ListenerTypeUnix, ListenerTypeFd, ListenerTypeSystemd}, foldEnum: true},
+	"listener.path":                        {description: "socket path of unix listener", defaultValue: ""},
+	"listener.mode":                        {description: "octal file mode of unix socket", defaultValue: ""},
+	"listener.fd":                          {description: "fd number of fd listener or socket index of systemd listener", defaultValue: 0},
+	"http2.h2c":                            {description: "accept HTTP/2 without TLS", defaultValue: false},
+	"http2.maxConcurrentStreams":           {description: "0 means default of golang.org/x/net/http2", defaultValue: 0},
+	"http2.maxReadFrameSize":               {description: "0 means default of golang.org/x/net/http2", defaultValue: 0},
+	"http2.idleTimeoutMs":                  {description: "0 means idleTimeoutMs of server", defaultValue: 0},
+	"proxyProtocol":                        {description: "HAProxy PROXY protocol v1/v2"},
+	"proxyProtocol.trustedCidrs":           {description: "parse header only from these sources, required if enabled", defaultValue: []interface{}{}},
+	"proxyProtocol.headerTimeoutMs":        {description: "max duration of reading header", defaultValue: 5000},
+	"management":                           {description: "dedicated listener of prom, pprof, sw, docs and commonService"},
+	"management.port":                      {description: "0 means management APIs are served on port of entry", defaultValue: 0},
+	"management.host":                      {defaultValue: "0.0.0.0"},
+	"management.certEntry":                 {description: "reference of cert entry declared above", defaultValue: ""},
+	"prom":                                 {description: "prometheus metrics"},
+	"prom.path":                            {defaultValue: "/metrics"},
+	"prom.pusher":                          {required: []string{"jobName", "remoteAddress"}},
+	"prom.pusher.basicAuth":                {description: "user:pass", defaultValue: ""},
+	"prom.pusher.intervalMs":               {defaultValue: 1000},
+	"prom.pusher.certEntry":                {description: "reference of cert entry declared above", defaultValue: ""},
+	"shutdown.preStopDelayMs":              {description: "ready endpoint returns 503 during the delay", defaultValue: 0},
+	"shutdown.gracePeriodMs":               {description: "max duration of draining in-flight requests", defaultValue: 5000},
+	"gracefulRestart.enabled":              {description: "re-exec and pass listeners to child process on SIGUSR2, not supported on windows", defaultValue: false},
+	"gracefulRestart.readyTimeoutMs":       {description: "max duration of waiting child process to be ready", defaultValue: 30000},
+	"middleware":                           {description: "middlewares of entry"},
+	"middleware.ignore":                    {description: "paths ignored by all middlewares", defaultValue: []interface{}{}},
+	"middleware.errorModel":                {description: "built-in error model or name registered with rkgin.RegisterErrorModel()", defaultValue: ErrorModelGoogle},
+	"middleware.order":                     {description: "reorder middlewares, missing ones are appended in default order", defaultValue: []interface{}{}},
+	"middleware.logging.loggerEncoding":    {defaultValue: "console", enum: []interface{}{"console", "json"}},
+	"middleware.logging.eventEncoding":     {defaultValue: "console", enum: []interface{}{"console", "json", "flatten"}},
+	"middleware.logging.loggerOutputPaths": {defaultValue: []interface{}{"stdout"}},
+	"middleware.logging.eventOutputPaths":  {defaultValue: []interface{}{"stdout"}},
+	"middleware.auth.basic":                {description: "credentials of basic auth like user:pass"},
+	"middleware.auth.apiKey":               {description: "API keys"},
+	"middleware.meta.prefix":               {defaultValue: "rk"},
+	"middleware.trace.exporter":            {description: "stdout exporter would be used if missing"},
+	"middleware.trace.exporter.file.outputPath": {defaultValue: "stdout"},
+	"middleware.rateLimit.algorithm":            {defaultValue: "tokenBucket", enum: []interface{}{"tokenBucket", "leakyBucket"}},
+	"middleware.rateLimit.reqPerSec":            {defaultValue: 1000000},
+	"middleware.rateLimit.paths.reqPerSec":      {defaultValue: 1000000},
+	"middleware.timeout.timeoutMs":              {defaultValue: 5000},
+	"middleware.timeout.paths.timeoutMs":        {defaultValue: 5000},
+	"middleware.jwt.signerEntry":                {description: "reference of signer entry declared above", defaultValue: ""},
+	"middleware.jwt.skipVerify":                 {defaultValue: false},
+	"middleware.jwt.symmetric":                  {required: []string{"algorithm"}},
+	"middleware.jwt.asymmetric":                 {required: []string{"algorithm"}},
+	"middleware.jwt.tokenLookup":                {defaultValue: "header:Authorization"},
+	"middleware.jwt.authScheme":                 {defaultValue: "Bearer"},
+	"middleware.secure.xssProtection":           {defaultValue: "1; mode=block"},
+	"middleware.secure.contentTypeNosniff":      {defaultValue: "nosniff"},
+	"middleware.secure.xFrameOptions":           {defaultValue: "SAMEORIGIN"},
+	"middleware.secure.hstsMaxAge":              {defaultValue: 0},
+	"middleware.csrf.tokenLength":               {defaultValue: 32},
+	"middleware.csrf.tokenLookup":               {defaultValue: "header:X-CSRF-Token"},
+	"middleware.csrf.cookieName":                {defaultValue: "_csrf"},
+	"middleware.csrf.cookieMaxAge":              {defaultValue: 86400},
+	"middleware.csrf.cookieSameSite":            {defaultValue: "default", enum: []interface{}{"default", "lax", "strict", "none"}, foldEnum: true},
+	"middleware.gzip.level":                     {enum: []interface{}{"noCompression", "bestSpeed", "bestCompression", "defaultCompression", "huffmanOnly"}, foldEnum: true},
+	"middleware.cors.allowOrigins":              {defaultValue: []interface{}{}},
+	"middleware.custom":                         {description: "middlewares created by factories registered with rkgin.RegisterMiddlewareFactory()", required: []string{"name"}},
+	"middleware.custom.name":                    {description: "name of factory registered with rkgin.RegisterMiddlewareFactory()"},
+	"middleware.custom.config":                  {description: "passed to factory, keys are lower-cased", defaultValue: map[string]interface{}{}},
+	"routeGroups":                               {description: "route groups with dedicated middlewares", required: []string{"name", "path"}},
+	"routeGroups.name":                          {description: "use GinEntry.Group(name) to register handlers"},
+	"routeGroups.path":                          {description: "path prefix of group"},
+	"introspection":                             {description: "endpoint of resolved config, middleware chain and routes of gin entries at <commonService.pathPrefix>/gin/entries"},
+	"routeGroups.middleware":                    {description: "same as middleware of entry except prom and errorModel"},
+	"middlewareReload":                          {description: "reload options of middlewares from boot config file without restarting server"},
+	"middlewareReload.enabled":                  {description: "reload on SIGHUP", defaultValue: false},
+	"middlewareReload.path":                     {description: "path of boot config file, required if enabled"},
+	"middlewareReload.intervalMs":               {description: "check modification of boot config file with interval if positive", defaultValue: 0},
+	"workers":                                   {description: "background workers registered with GinEntry.AddWorker() and GinEntry.AddCron()"},
+	"workers.drainTimeoutMs":                    {description: "max duration of waiting workers to return after cancelled on shutdown", defaultValue: 5000},
+	"workers.jobs":                              {description: "override schedule of workers with the same name", required: []string{"name"}},
+	"workers.jobs.name":                         {description: "name of worker registered in code"},
+	"workers.jobs.cron":                         {description: "run worker on cron spec", examples: []interface{}{"*/5 * * * *", "@hourly", "@every 30s"}},
+	"workers.jobs.disabled":                     {description: "skip worker", defaultValue: false},
+}
+
+// NewBootGinSchema returns JSON Schema of boot config which could be used by IDEs and CI to validate boot YAML.
+//
+// Keys are named after YAML tags of BootGin and nested BootConfig of rk-entry, while other sections of boot YAML
+// like logger and cert are allowed as they are. Keys and enum values matched case-insensitively by decoder and
+// ValidateBootGin are matched case-insensitively as well, with patternProperties referring to named properties.
+func NewBootGinSchema() *JSONSchema {
+	element := schemaOf(reflect.TypeOf(BootGinElement{}), "", "#/properties/gin/items")
+	element.Description = "gin entry"
+	element.Required = []string{"name", "port", "enabled"}
+
+	return &JSONSchema{
+		Schema:      JSONSchemaDraft07,
+		Title:       "rk-gin boot config",
+		Description: "boot config of gin entries",
+		Type:        "object",
+		Properties: map[string]*JSONSchema{
+			"gin": {
+				Description: "gin entries",
+				Type:        "array",
+				Items:       element,
+			},
+		},
+		PatternProperties: map[string]*JSONSchema{
+			"^" + foldPattern("gin") + "$": {Ref: "#/properties/gin"},
+		},
+		AdditionalProperties: true,
+	}
+}
+
+// schemaOf generates JSON Schema of type, path is used to look up docs of nested keys and ptr is JSON pointer of
+// generated schema in document.
+func schemaOf(typ reflect.Type, path, ptr string) *JSONSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	// route groups share docs of middleware
+	if typ == reflect.TypeOf(BootMiddleware{}) {
+		path = "middleware"
+	}
+
+	res := &JSONSchema{}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		res.Type = "object"
+		res.AdditionalProperties = false
+		res.Properties = make(map[string]*JSONSchema)
+		res.PatternProperties = make(map[string]*JSONSchema)
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			if len(name) < 1 {
+				name = field.Name
+			}
+			// keys are matched case-insensitively while decoding, use lower camel case consistently
+			name = strings.ToLower(name[:1]) + name[1:]
+
+			propPath := name
+			if len(path) > 0 {
+				propPath = path + "." + name
+			}
+
+			prop := schemaOf(field.Type, propPath, ptr+"/properties/"+name)
+			applySchemaDoc(prop, propPath, path)
+			res.Properties[name] = prop
+			res.PatternProperties["^"+foldPattern(name)+"$"] = &JSONSchema{Ref: ptr + "/properties/" + name}
+		}
+	case reflect.Slice, reflect.Array:
+		res.Type = "array"
+		res.Items = schemaOf(typ.Elem(), path, ptr+"/items")
+	case reflect.Map:
+		res.Type = "object"
+		if typ.Elem().Kind() == reflect.Interface {
+			res.AdditionalProperties = true
+		} else {
+			res.AdditionalProperties = schemaOf(typ.Elem(), path, ptr+"/additionalProperties")
+		}
+	case reflect.Bool:
+		res.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		res.Type = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		res.Type = "integer"
+		min := 0
+		res.Minimum = &min
+	case reflect.Float32, reflect.Float64:
+		res.Type = "number"
+	case reflect.String:
+		res.Type = "string"
+	}
+
+	return res
+}
+
+// applySchemaDoc fills description, default value and options of key.
+func applySchemaDoc(schema *JSONSchema, path, parent string) {
+	doc, ok := schemaDocs[path]
+	if !ok {
+		parent = parent[strings.LastIndex(parent, ".")+1:]
+		// enabled and ignore are common keys of BootConfig
+		switch path[strings.LastIndex(path, ".")+1:] {
+		case "enabled":
+			doc = schemaDoc{description: "enable " + parent, defaultValue: false}
+		case "ignore":
+			doc = schemaDoc{description: "paths ignored by " + parent, defaultValue: []interface{}{}}
+		}
+	}
+
+	schema.Description = doc.description
+	schema.Default = doc.defaultValue
+	schema.Enum = doc.enum
+	schema.Examples = doc.examples
+
+	// options are kept as examples for completion of IDEs
+	if doc.foldEnum {
+		options := make([]string, 0, len(doc.enum))
+		for i := range doc.enum {
+			options = append(options, foldPattern(doc.enum[i].(string)))
+		}
+		schema.Enum = nil
+		schema.Examples = append(append([]interface{}{}, doc.enum...), doc.examples...)
+		schema.Pattern = "^(" + strings.Join(options, "|") + ")$"
+	}
+
+	target := schema
+	if schema.Items != nil {
+		target = schema.Items
+	}
+	target.Required = doc.required
+
+	if path == "middleware.errorModel" {
+		for _, name := range ListErrorModels() {
+			schema.Examples = append(schema.Examples, name)
+		}
+	}
+}
+
+// foldPattern returns regular expression without anchors matching s case-insensitively, since (?i) is not supported
+// by ECMA 262 regular expressions of JSON Schema.
+func foldPattern(s string) string {
+	res := strings.Builder{}
+	for _, r := range s {
+		if upper, lower := unicode.ToUpper(r), unicode.ToLower(r); upper != lower {
+			res.WriteString("[" + string(upper) + string(lower) + "]")
+			continue
+		}
+		res.WriteString(regexp.QuoteMeta(string(r)))
+	}
+
+	return res.String()
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewBootGinSchema(t *testing.T) {
+	schema := NewBootGinSchema()
+	assert.Equal(t, JSONSchemaDraft07, schema.Schema)
+	assert.Equal(t, true, schema.AdditionalProperties)
+	assert.Equal(t, "array", schema.Properties["gin"].Type)
+
+	element := schema.Properties["gin"].Items
+	assert.Equal(t, "object", element.Type)
+	assert.Equal(t, false, element.AdditionalProperties)
+	assert.Equal(t, []string{"name", "port", "enabled"}, element.Required)
+
+	// types
+	assert.Equal(t, "integer", element.Properties["port"].Type)
+	assert.Equal(t, 0, *element.Properties["port"].Minimum)
+	assert.Equal(t, "string", element.Properties["name"].Type)
+	assert.Equal(t, "array", element.Properties["trustedProxies"].Type)
+	assert.Equal(t, "string", element.Properties["trustedProxies"].Items.Type)
+
+	// defaults
+	assert.Equal(t, 5000, element.Properties["shutdown"].Properties["gracePeriodMs"].Default)
+	assert.Equal(t, "0.0.0.0", element.Properties["server"].Properties["host"].Default)
+
+	// nested BootConfig of rk-entry
+	mid := element.Properties["middleware"]
+	assert.Equal(t, "boolean", mid.Properties["jwt"].Properties["enabled"].Type)
+	assert.Equal(t, false, mid.Properties["jwt"].Properties["enabled"].Default)
+	assert.Equal(t, "enable jwt", mid.Properties["jwt"].Properties["enabled"].Description)
+	assert.Equal(t, "paths ignored by jwt", mid.Properties["jwt"].Properties["ignore"].Description)
+	assert.Equal(t, []string{"algorithm"}, mid.Properties["jwt"].Properties["symmetric"].Required)
+	assert.Contains(t, mid.Properties["errorModel"].Examples, ErrorModelRfc7807)
+
+	// enums, options parsed case-insensitively are matched with pattern
+	assert.Contains(t, element.Properties["mode"].Enum, "release")
+	assert.Contains(t, mid.Properties["gzip"].Properties["level"].Examples, "bestSpeed")
+	assert.Regexp(t, mid.Properties["gzip"].Properties["level"].Pattern, "BestSpeed")
+	assert.NotRegexp(t, mid.Properties["gzip"].Properties["level"].Pattern, "fastest")
+	assert.Contains(t, mid.Properties["csrf"].Properties["cookieSameSite"].Examples, "lax")
+	assert.Contains(t, element.Properties["listener"].Properties["type"].Examples, ListenerTypeUnix)
+
+	// keys are matched case-insensitively
+	assert.Equal(t, "#/properties/gin/items/properties/middleware/properties/rateLimit",
+		mid.PatternProperties["^[Rr][Aa][Tt][Ee][Ll][Ii][Mm][Ii][Tt]$"].Ref)
+
+	// free form config of custom middleware
+	custom := mid.Properties["custom"]
+	assert.Equal(t, []string{"name"}, custom.Items.Required)
+	assert.Equal(t, true, custom.Items.Properties["config"].AdditionalProperties)
+
+	// route groups share docs of middleware
+	group := element.Properties["routeGroups"].Items
+	assert.Equal(t, []string{"name", "path"}, group.Required)
+	assert.Equal(t, mid.Properties["gzip"].Properties["level"].Pattern,
+		group.Properties["middleware"].Properties["gzip"].Properties["level"].Pattern)
+	assert.Equal(t, schemaDocs["routeGroups.middleware"].description, group.Properties["middleware"].Description)
+
+	// false and 0 defaults are kept
+	bytes, err := json.Marshal(schema)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), `"default":false`)
+	assert.Contains(t, string(bytes), `"default":0`)
+}
+
+func TestSchemaDocs_Paths(t *testing.T) {
+	element := NewBootGinSchema().Properties["gin"].Items
+
+	for path := range schemaDocs {
+		schema := element
+		for _, key := range strings.Split(path, ".") {
+			if schema.Items != nil {
+				schema = schema.Items
+			}
+			schema = schema.Properties[key]
+			if !assert.NotNil(t, schema, path) {
+				break
+			}
+		}
+	}
+}
+
+func TestNewBootGinSchema_AcceptedByValidateBootGin(t *testing.T) {
+	fixtures := []string{
+		defaultBootConfigStr,
+		`
+gin:
+  - name: ut-schema-case
+    port: 1949
+    enabled: true
+    Description: ut
+    middleware:
+      ratelimit:
+        enabled: true
+      gzip:
+        enabled: true
+        level: BestSpeed
+      csrf:
+        enabled: true
+        cookieSameSite: Lax
+    tls:
+      clientauth: VerifyIfGiven
+    routeGroups:
+      - name: admin
+        path: /api/admin
+        middleware:
+          order: ["ratelimit", "auth"]
+          AUTH:
+            enabled: true
+            basic: ["user:pass"]
+          ratelimit:
+            enabled: true
+`,
+		`
+Gin:
+  - Name: ut-schema-case-gin
+    PORT: 1949
+    enabled: true
+`,
+	}
+
+	schema := NewBootGinSchema()
+	for i := range fixtures {
+		assert.Empty(t, ValidateBootGin([]byte(fixtures[i])), fixtures[i])
+
+		raw := map[interface{}]interface{}{}
+		assert.Nil(t, yaml.Unmarshal([]byte(fixtures[i]), &raw))
+		assert.Empty(t, validateSchema(schema, schema, raw, "$"), fixtures[i])
+	}
+
+	// unknown key and invalid enum are rejected either
+	raw := map[interface{}]interface{}{}
+	assert.Nil(t, yaml.Unmarshal([]byte(`
+gin:
+  - name: ut-schema-invalid
+    port: 1949
+    enabled: true
+    unknown: true
+    middleware:
+      gzip:
+        level: fastest
+`), &raw))
+	assert.Equal(t, []string{
+		"$.gin[0].middleware.gzip.level: not matched",
+		"$.gin[0].unknown: unknown key",
+	}, validateSchema(schema, schema, raw, "$"))
+}
+
+// validateSchema validates value with the subset of JSON Schema generated by NewBootGinSchema.
+func validateSchema(root, schema *JSONSchema, value interface{}, path string) []string {
+	if len(schema.Ref) > 0 {
+		ref := root
+		for _, key := range strings.Split(strings.TrimPrefix(schema.Ref, "#/"), "/") {
+			switch key {
+			case "properties":
+			case "items":
+				ref = ref.Items
+			case "additionalProperties":
+				ref = ref.AdditionalProperties.(*JSONSchema)
+			default:
+				ref = ref.Properties[key]
+			}
+		}
+		schema = ref
+	}
+
+	res := make([]string, 0)
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, fmt.Sprintf("%v", key))
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			subs := make([]*JSONSchema, 0)
+			if prop, ok := schema.Properties[key]; ok {
+				subs = append(subs, prop)
+			}
+			for pattern, prop := range schema.PatternProperties {
+				if regexp.MustCompile(pattern).MatchString(key) {
+					subs = append(subs, prop)
+				}
+			}
+			if len(subs) < 1 {
+				switch additional := schema.AdditionalProperties.(type) {
+				case *JSONSchema:
+					subs = append(subs, additional)
+				case bool:
+					if !additional {
+						res = append(res, path+"."+key+": unknown key")
+					}
+				}
+			}
+
+			// properties and patternProperties refer to the same schema
+			if len(subs) > 0 {
+				res = append(res, validateSchema(root, subs[0], v[key], path+"."+key)...)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i := range v {
+				res = append(res, validateSchema(root, schema.Items, v[i], fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		if len(schema.Pattern) > 0 && !regexp.MustCompile(schema.Pattern).MatchString(v) {
+			res = append(res, path+": not matched")
+		}
+		if len(schema.Enum) > 0 && !containsValue(schema.Enum, v) {
+			res = append(res, path+": not in enum")
+		}
+	}
+
+	return res
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for i := range list {
+		if list[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultGracePeriod max duration of waiting in-flight requests while shutting down
+	defaultGracePeriod = 5 * time.Second
+)
+
+// BootShutdown bootstrap config of graceful shutdown.
+//
+// While shutting down, ready endpoint of CommonServiceEntry would return 503 first, entry waits PreStopDelayMs
+// so that load balancers could stop routing traffic, and then drains in-flight requests of gin server and management
+// server within GracePeriodMs.
+type BootShutdown struct {
+	PreStopDelayMs int `yaml:"preStopDelayMs" json:"preStopDelayMs"`
+	GracePeriodMs  int `yaml:"gracePeriodMs" json:"gracePeriodMs"`
+}
+
+// IsDraining returns true if entry started shutting down.
+func (entry *GinEntry) IsDraining() bool {
+	return atomic.LoadInt32(&entry.draining) == 1
+}
+
+// InFlightRequests returns number of requests being served by entry.
+func (entry *GinEntry) InFlightRequests() int64 {
+	return atomic.LoadInt64(&entry.inFlight)
+}
+
+// trackInFlight wraps handler and counts requests in flight.
+func (entry *GinEntry) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&entry.inFlight, 1)
+		defer atomic.AddInt64(&entry.inFlight, -1)
+
+		next.ServeHTTP(writer, req)
+	})
+}
+
+// registerInFlightGauge exports number of in-flight requests to PromEntry.
+func (entry *GinEntry) registerInFlightGauge() {
+	entry.registerCollector(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   "rk",
+		Subsystem:   "gin",
+		Name:        "in_flight_requests",
+		Help:        "Number of requests being served.",
+		ConstLabels: prometheus.Labels{"entryName": entry.entryName},
+	}, func() float64 {
+		return float64(entry.InFlightRequests())
+	}))
+}
+
+// ready returns 503 while entry is draining or critical health checks failed, otherwise delegates to
+// CommonServiceEntry.
+func (entry *GinEntry) ready(writer http.ResponseWriter, req *http.Request) {
+	if entry.IsDraining() {
+		entry.writeError(writer, http.StatusServiceUnavailable, "Server is shutting down")
+		return
+	}
+
+	if failed := entry.failedCriticalChecks(req.Context()); len(failed) > 0 {
+		entry.writeError(writer, http.StatusServiceUnavailable,
+			fmt.Sprintf("Critical health checks failed: %s", strings.Join(failed, ", ")))
+		return
+	}
+
+	entry.CommonServiceEntry.Ready(writer, req)
+}
+
+// startDraining flips readiness and waits for pre-stop delay.
+func (entry *GinEntry) startDraining(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&entry.draining, 0, 1) {
+		return
+	}
+
+	if entry.preStopDelay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(entry.preStopDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// waitInFlight waits until requests in flight of entry finished or ctx is done.
+//
+// It is used if server of entry could not be shut down, like listener shared with other entries.
+func (entry *GinEntry) waitInFlight(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for entry.InFlightRequests() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// WithPreStopDelay provide duration to wait after readiness flipped and before server stops accepting requests.
+func WithPreStopDelay(delay time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if delay > 0 {
+			entry.preStopDelay = delay
+		}
+	}
+}
+
+// WithGracePeriod provide max duration of draining in-flight requests.
+func WithGracePeriod(period time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if period > 0 {
+			entry.gracePeriod = period
+		}
+	}
+}
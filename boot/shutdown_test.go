@@ -0,0 +1,105 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithGracePeriod(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-grace"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, defaultGracePeriod, entry.gracePeriod)
+	assert.Zero(t, entry.preStopDelay)
+
+	entry = RegisterGinEntry(
+		WithName("ut-grace-2"),
+		WithPreStopDelay(time.Second),
+		WithGracePeriod(10*time.Second))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, 10*time.Second, entry.gracePeriod)
+	assert.Equal(t, time.Second, entry.preStopDelay)
+}
+
+func TestGinEntry_GracefulDrain(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	entry := RegisterGinEntry(
+		WithName("ut-drain"),
+		WithPort(8087),
+		WithPreStopDelay(time.Second),
+		WithGracePeriod(5*time.Second),
+		WithCommonServiceEntry(rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+			Enabled: true,
+		})),
+		WithPromEntry(rkentry.RegisterPromEntry(&rkentry.BootProm{
+			Enabled: true,
+		}, rkentry.WithRegistryPromEntry(registry))))
+
+	started := make(chan struct{})
+	entry.Router.GET("/ut-slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(2 * time.Second)
+		ctx.String(http.StatusOK, "done")
+	})
+
+	entry.Bootstrap(context.TODO())
+	time.Sleep(time.Second)
+
+	resp, err := http.Get("http://localhost:8087/rk/v1/ready")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	// start a slow request
+	slowDone := make(chan int)
+	go func() {
+		resp, err := http.Get("http://localhost:8087/ut-slow")
+		if err != nil {
+			slowDone <- 0
+			return
+		}
+		resp.Body.Close()
+		slowDone <- resp.StatusCode
+	}()
+	<-started
+	assert.Equal(t, int64(1), entry.InFlightRequests())
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP rk_gin_in_flight_requests Number of requests being served.
+# TYPE rk_gin_in_flight_requests gauge
+rk_gin_in_flight_requests{entryName="ut-drain"} 1
+`), "rk_gin_in_flight_requests"))
+
+	// interrupt, readiness should flip before server stops
+	interrupted := make(chan struct{})
+	go func() {
+		entry.Interrupt(context.TODO())
+		close(interrupted)
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	assert.True(t, entry.IsDraining())
+	resp, err = http.Get("http://localhost:8087/rk/v1/ready")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+
+	// in-flight request is drained
+	assert.Equal(t, http.StatusOK, <-slowDone)
+	<-interrupted
+	assert.Zero(t, entry.InFlightRequests())
+}
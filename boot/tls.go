@@ -0,0 +1,333 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	// ClientAuthNone do not request client certificate
+	ClientAuthNone = "none"
+	// ClientAuthRequest request client certificate, but not required and not verified
+	ClientAuthRequest = "request"
+	// ClientAuthRequire require client certificate, but not verified
+	ClientAuthRequire = "require"
+	// ClientAuthVerify require client certificate and verify it with CA in CertEntry
+	ClientAuthVerify = "verify"
+	// ClientAuthVerifyIfGiven verify client certificate with CA in CertEntry if client provided one
+	ClientAuthVerifyIfGiven = "verifyIfGiven"
+
+	// certListenerServer label of certificate served by gin server
+	certListenerServer = "server"
+	// certListenerManagement label of certificate served by management server
+	certListenerManagement = "management"
+)
+
+// BootTLS bootstrap config of TLS for GinEntry.
+type BootTLS struct {
+	ClientAuth   string   `yaml:"clientAuth" json:"clientAuth"`
+	MinVersion   string   `yaml:"minVersion" json:"minVersion"`
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites"`
+	NextProtos   []string `yaml:"nextProtos" json:"nextProtos"`
+	Reload       struct {
+		Enabled    bool `yaml:"enabled" json:"enabled"`
+		IntervalMs int  `yaml:"intervalMs" json:"intervalMs"`
+	} `yaml:"reload" json:"reload"`
+}
+
+// ToOptions convert BootTLS into GinEntryOption list.
+func (boot *BootTLS) ToOptions() ([]GinEntryOption, error) {
+	opts := make([]GinEntryOption, 0)
+
+	clientAuth, err := ParseClientAuth(boot.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithClientAuth(clientAuth))
+
+	minVersion, err := ParseTlsVersion(boot.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithTlsMinVersion(minVersion))
+
+	suites, err := ParseCipherSuites(boot.CipherSuites...)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithCipherSuites(suites...), WithNextProtos(boot.NextProtos...))
+
+	if boot.Reload.Enabled {
+		interval := time.Duration(boot.Reload.IntervalMs) * time.Millisecond
+		if interval <= 0 {
+			interval = defaultCertReloadInterval
+		}
+		opts = append(opts, WithCertReloadInterval(interval))
+	}
+
+	return opts, nil
+}
+
+// ParseClientAuth convert client auth mode into tls.ClientAuthType.
+//
+// Supported modes: none, request, require, verify, verifyIfGiven
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case ClientAuthVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	case strings.ToLower(ClientAuthVerifyIfGiven):
+		return tls.VerifyClientCertIfGiven, nil
+	}
+
+	return tls.NoClientCert, fmt.Errorf("invalid tls clientAuth %q, expect one of [%s, %s, %s, %s, %s]",
+		mode, ClientAuthNone, ClientAuthRequest, ClientAuthRequire, ClientAuthVerify, ClientAuthVerifyIfGiven)
+}
+
+// ParseTlsVersion convert TLS version like 1.2 or TLS1.2 into uint16 defined in crypto/tls.
+//
+// Returns 0 if version is empty which means default of crypto/tls would be used.
+func ParseTlsVersion(version string) (uint16, error) {
+	v := strings.TrimPrefix(strings.ToLower(version), "tls")
+	v = strings.TrimPrefix(v, "v")
+
+	switch v {
+	case "":
+		return 0, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("invalid tls minVersion %q, expect one of [1.0, 1.1, 1.2, 1.3]", version)
+}
+
+// ParseCipherSuites convert cipher suite names like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 into IDs.
+//
+// Both secure and insecure cipher suites provided by crypto/tls are accepted.
+func ParseCipherSuites(names ...string) ([]uint16, error) {
+	res := make([]uint16, 0)
+
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	for i := range names {
+		id, ok := suites[strings.ToUpper(names[i])]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls cipher suite %q", names[i])
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
+
+// ClientAuthToString convert tls.ClientAuthType into modes described in ParseClientAuth.
+func ClientAuthToString(clientAuth tls.ClientAuthType) string {
+	switch clientAuth {
+	case tls.RequestClientCert:
+		return ClientAuthRequest
+	case tls.RequireAnyClientCert:
+		return ClientAuthRequire
+	case tls.RequireAndVerifyClientCert:
+		return ClientAuthVerify
+	case tls.VerifyClientCertIfGiven:
+		return ClientAuthVerifyIfGiven
+	}
+
+	return ClientAuthNone
+}
+
+// ReloadCertificate reloads server certificates of gin server and management server from PEM files of their
+// CertEntry and swaps them without restarting servers.
+//
+// If certificate was not loaded from local files, CertEntry.Certificate would be served instead,
+// so user could replace CertEntry.Certificate in code and call this function.
+//
+// Reloaded certificate is only served by certReloader, CertEntry is shared with other entries and is not modified.
+func (entry *GinEntry) ReloadCertificate() error {
+	if !entry.IsTlsEnabled() && !entry.IsManagementTlsEnabled() {
+		return errors.New("TLS is not enabled")
+	}
+
+	var res error
+	if entry.IsTlsEnabled() {
+		res = entry.reloadCertificate(certListenerServer, entry.getCertReloader(), entry.CertEntry)
+	}
+
+	if entry.IsManagementTlsEnabled() {
+		err := entry.reloadCertificate(certListenerManagement, entry.getMgmtCertReloader(), entry.ManagementCertEntry)
+		if res == nil {
+			res = err
+		}
+	}
+
+	return res
+}
+
+// reloadCertificate reloads certificate of listener and records its expiration time.
+func (entry *GinEntry) reloadCertificate(listener string, reloader *certReloader, certEntry *rkentry.CertEntry) error {
+	event, logger := entry.logBasicInfo("ReloadCertificate", context.Background())
+	event.AddPayloads(zap.String("listener", listener))
+
+	cert, err := reloader.reload(certEntry.Certificate)
+	if err != nil {
+		event.AddErr(err)
+		logger.Warn("Error occurs while reloading certificate.", event.ListPayloads()...)
+		entry.EventEntry.FinishWithCond(event, false)
+		return err
+	}
+
+	notAfter := certNotAfter(cert)
+	entry.observeCertNotAfter(listener, notAfter)
+
+	event.AddPayloads(zap.Time("certNotAfter", notAfter))
+	logger.Info("Certificate reloaded.", zap.String("listener", listener), zap.Time("certNotAfter", notAfter))
+	entry.EventEntry.Finish(event)
+
+	return nil
+}
+
+// getCertReloader returns certReloader of gin server, creates one if missing.
+func (entry *GinEntry) getCertReloader() *certReloader {
+	entry.certReloaderOnce.Do(func() {
+		entry.certReloader = newCertReloader(entry.CertEntry)
+	})
+
+	return entry.certReloader
+}
+
+// getMgmtCertReloader returns certReloader of management server, creates one if missing.
+func (entry *GinEntry) getMgmtCertReloader() *certReloader {
+	entry.mgmtCertReloadOnce.Do(func() {
+		entry.mgmtCertReloader = newCertReloader(entry.ManagementCertEntry)
+	})
+
+	return entry.mgmtCertReloader
+}
+
+// startCertReloader registers expiration gauge and starts watching PEM files of CertEntry of gin server and
+// management server.
+func (entry *GinEntry) startCertReloader() {
+	entry.certNotAfterGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "rk",
+		Subsystem: "gin",
+		Name:      "cert_not_after_seconds",
+		Help:      "Expiration time of server certificate in unix seconds.",
+	}, []string{"entryName", "listener"})
+	entry.certNotAfterGauge, _ = entry.registerCollector(entry.certNotAfterGauge).(*prometheus.GaugeVec)
+
+	if entry.IsTlsEnabled() {
+		entry.observeCertNotAfter(certListenerServer, certNotAfter(entry.CertEntry.Certificate))
+		entry.getCertReloader().watch(entry.certReloadInterval, func() {
+			// error was recorded in event already
+			_ = entry.reloadCertificate(certListenerServer, entry.getCertReloader(), entry.CertEntry)
+		})
+	}
+
+	if entry.IsManagementTlsEnabled() {
+		entry.observeCertNotAfter(certListenerManagement, certNotAfter(entry.ManagementCertEntry.Certificate))
+		entry.getMgmtCertReloader().watch(entry.certReloadInterval, func() {
+			// error was recorded in event already
+			_ = entry.reloadCertificate(certListenerManagement, entry.getMgmtCertReloader(), entry.ManagementCertEntry)
+		})
+	}
+}
+
+// observeCertNotAfter records expiration time of certificate served by listener.
+func (entry *GinEntry) observeCertNotAfter(listener string, notAfter time.Time) {
+	if entry.certNotAfterGauge != nil && !notAfter.IsZero() {
+		entry.certNotAfterGauge.WithLabelValues(entry.entryName, listener).Set(float64(notAfter.Unix()))
+	}
+}
+
+// newTlsConfig creates tls.Config based on CertEntry and TLS options.
+//
+// CA loaded by CertEntry would be used to verify client certificates.
+// Server certificate is served by certReloader, so it could be rotated without restarting server.
+func (entry *GinEntry) newTlsConfig() *tls.Config {
+	conf := &tls.Config{
+		GetCertificate: entry.getCertReloader().GetCertificate,
+		ClientAuth:     entry.clientAuth,
+		MinVersion:     entry.tlsMinVersion,
+		CipherSuites:   entry.cipherSuites,
+		NextProtos:     entry.nextProtos,
+	}
+
+	if entry.CertEntry.RootCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(entry.CertEntry.RootCA)
+		conf.ClientCAs = pool
+	}
+
+	return conf
+}
+
+// WithClientAuth provide client authentication policy of TLS.
+func WithClientAuth(clientAuth tls.ClientAuthType) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.clientAuth = clientAuth
+	}
+}
+
+// WithTlsMinVersion provide minimum TLS version, like tls.VersionTLS12.
+func WithTlsMinVersion(version uint16) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.tlsMinVersion = version
+	}
+}
+
+// WithCipherSuites provide list of enabled TLS 1.0–1.2 cipher suites.
+func WithCipherSuites(suites ...uint16) GinEntryOption {
+	return func(entry *GinEntry) {
+		if len(suites) > 0 {
+			entry.cipherSuites = suites
+		}
+	}
+}
+
+// WithCertReloadInterval provide interval of checking PEM files of CertEntry, certificate would be reloaded if changed.
+func WithCertReloadInterval(interval time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if interval > 0 {
+			entry.certReloadInterval = interval
+		}
+	}
+}
+
+// WithNextProtos provide list of supported application level protocols (ALPN).
+func WithNextProtos(protos ...string) GinEntryOption {
+	return func(entry *GinEntry) {
+		if len(protos) > 0 {
+			entry.nextProtos = protos
+		}
+	}
+}
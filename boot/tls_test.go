@@ -0,0 +1,206 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseClientAuth(t *testing.T) {
+	cases := map[string]tls.ClientAuthType{
+		"":              tls.NoClientCert,
+		"none":          tls.NoClientCert,
+		"request":       tls.RequestClientCert,
+		"require":       tls.RequireAnyClientCert,
+		"verify":        tls.RequireAndVerifyClientCert,
+		"verifyIfGiven": tls.VerifyClientCertIfGiven,
+	}
+
+	for mode, expected := range cases {
+		res, err := ParseClientAuth(mode)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, res)
+		if len(mode) > 0 {
+			assert.Equal(t, mode, ClientAuthToString(res))
+		}
+	}
+
+	_, err := ParseClientAuth("invalid")
+	assert.NotNil(t, err)
+}
+
+func TestParseTlsVersion(t *testing.T) {
+	v, err := ParseTlsVersion("")
+	assert.Nil(t, err)
+	assert.Zero(t, v)
+
+	v, err = ParseTlsVersion("1.2")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), v)
+
+	v, err = ParseTlsVersion("TLS1.3")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), v)
+
+	_, err = ParseTlsVersion("2.0")
+	assert.NotNil(t, err)
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	res, err := ParseCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, res)
+
+	_, err = ParseCipherSuites("invalid")
+	assert.NotNil(t, err)
+}
+
+func TestBootTLS_ToOptions(t *testing.T) {
+	// happy case
+	boot := &BootTLS{
+		ClientAuth:   "verify",
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		NextProtos:   []string{"http/1.1"},
+	}
+	opts, err := boot.ToOptions()
+	assert.Nil(t, err)
+
+	entry := &GinEntry{}
+	for i := range opts {
+		opts[i](entry)
+	}
+	assert.Equal(t, tls.RequireAndVerifyClientCert, entry.clientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), entry.tlsMinVersion)
+	assert.Len(t, entry.cipherSuites, 1)
+	assert.Equal(t, []string{"http/1.1"}, entry.nextProtos)
+
+	// with invalid values
+	_, err = (&BootTLS{ClientAuth: "invalid"}).ToOptions()
+	assert.NotNil(t, err)
+	_, err = (&BootTLS{MinVersion: "invalid"}).ToOptions()
+	assert.NotNil(t, err)
+	_, err = (&BootTLS{CipherSuites: []string{"invalid"}}).ToOptions()
+	assert.NotNil(t, err)
+}
+
+func TestGinEntry_MutualTls(t *testing.T) {
+	ca, caKey := generateCA()
+	serverCert := generateLeaf(ca, caKey, "localhost", nil)
+	spiffeId, _ := url.Parse("spiffe://example.org/ut-client")
+	clientCert := generateLeaf(ca, caKey, "ut-client", spiffeId)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name: "ut-cert-mtls",
+			},
+		},
+	})[0]
+	certEntry.Certificate = &serverCert
+	certEntry.RootCA = ca
+
+	entry := RegisterGinEntry(
+		WithName("ut-mtls"),
+		WithPort(8083),
+		WithCertEntry(certEntry),
+		WithClientAuth(tls.RequireAndVerifyClientCert),
+		WithTlsMinVersion(tls.VersionTLS12))
+	entry.Router.GET("/ut-peer", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, rkginctx.GetPeerIdentity(ctx).SpiffeId)
+	})
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+	time.Sleep(time.Second)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	// without client certificate
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+	_, err := client.Get("https://localhost:8083/ut-peer")
+	assert.NotNil(t, err)
+
+	// with client certificate
+	client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      pool,
+				Certificates: []tls.Certificate{clientCert},
+			},
+		},
+	}
+	resp, err := client.Get("https://localhost:8083/ut-peer")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, spiffeId.String(), string(body))
+}
+
+func generateCA() (*x509.Certificate, *rsa.PrivateKey) {
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ut-ca"},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(2 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	raw, _ := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	ca, _ := x509.ParseCertificate(raw)
+
+	return ca, key
+}
+
+func generateLeaf(ca *x509.Certificate, caKey *rsa.PrivateKey, cn string, uri *url.URL) tls.Certificate {
+	tmpl := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: cn},
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(2 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if uri != nil {
+		tmpl.URIs = []*url.URL{uri}
+	}
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	raw, _ := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+
+	return tls.Certificate{
+		Certificate: [][]byte{raw},
+		PrivateKey:  key,
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/gzip"
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	gzipLevels = []string{
+		rkgingzip.NoCompression,
+		rkgingzip.BestSpeed,
+		rkgingzip.BestCompression,
+		rkgingzip.DefaultCompression,
+		rkgingzip.HuffmanOnly,
+	}
+
+	cookieSameSites = []string{"default", "lax", "strict", "none"}
+)
+
+// ValidationError is a problem found in boot YAML with path of the key, like gin[0].middleware.gzip.level
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error returns path and message
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Path, err.Message)
+}
+
+// ValidateBootGin validates gin section of boot YAML and returns all problems found, nil if config is valid.
+//
+// Unknown keys, duplicate entry names, port collisions between enabled entries, invalid enum values and references
+// to cert, logger and event entries which were not registered are reported.
+//
+// Values are validated after overrides from environment variables and --rkset flag are applied, the same as
+// rkentry.UnmarshalBootYAML does, while unknown keys are reported with paths in boot YAML.
+func ValidateBootGin(raw []byte) []error {
+	rawM := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(raw, &rawM); err != nil {
+		return []error{err}
+	}
+
+	// lower-cased keys with overrides applied
+	mergedM := map[interface{}]interface{}{}
+	rkentry.UnmarshalBootYAML(raw, &mergedM)
+
+	if _, ok := mergedM["gin"]; !ok {
+		return nil
+	}
+
+	v := &validator{}
+
+	// 1: unknown keys
+	if _, ok := rawM["gin"]; ok {
+		v.validateKeys("gin", rawM["gin"], reflect.TypeOf(BootGin{}.Gin))
+	}
+
+	// 2: decode the same way as rkentry.UnmarshalBootYAML
+	config := &BootGin{}
+	if err := mapstructure.Decode(map[interface{}]interface{}{"gin": mergedM["gin"]}, config); err != nil {
+		v.add("gin", "%v", err)
+		return v.errs
+	}
+
+	// 3: values
+	names := make(map[string]int)
+	binds := make([]*validatorBind, 0)
+
+	for i, element := range config.Gin {
+		if element == nil || !element.Enabled {
+			continue
+		}
+
+		path := fmt.Sprintf("gin[%d]", i)
+
+		if prev, ok := names[element.Name]; ok {
+			v.add(path+".name", "duplicate entry name %q, already used by gin[%d]", element.Name, prev)
+		} else {
+			names[element.Name] = i
+		}
+
+		v.validateElement(path, element)
+
+		// entries listen on unix socket, file descriptor or systemd socket do not bind port
+		if listenerType := strings.ToLower(element.Listener.Type); listenerType == "" || listenerType == ListenerTypeTcp {
+			bind := &validatorBind{
+				path: path + ".port", host: element.Server.Host, port: element.Port, tls: len(element.CertEntry) > 0,
+			}
+			for _, host := range element.Hosts {
+				bind.hosts = append(bind.hosts, normalizeHost(host))
+			}
+			binds = append(binds, bind)
+		}
+
+		if element.Management.Port > 0 {
+			binds = append(binds, &validatorBind{
+				path: path + ".management.port", host: element.Management.Host, port: element.Management.Port,
+			})
+		}
+	}
+
+	// 4: port collisions, port 0 means random port, entries with hosts could share the same address
+	for i := range binds {
+		for j := 0; j < i; j++ {
+			if binds[i].port > 0 && binds[i].collides(binds[j]) {
+				if msg := binds[i].conflict(binds[j]); len(msg) > 0 {
+					v.add(binds[i].path, "%s", msg)
+					break
+				}
+			}
+		}
+	}
+
+	return v.errs
+}
+
+// joinValidationErrors joins errors returned by ValidateBootGin into one error.
+func joinValidationErrors(errs []error) error {
+	msgs := make([]string, 0, len(errs))
+	for i := range errs {
+		msgs = append(msgs, errs[i].Error())
+	}
+
+	return fmt.Errorf("invalid boot config of gin:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+// validator collects errors of ValidateBootGin.
+type validator struct {
+	errs []error
+}
+
+// add appends ValidationError with path.
+func (v *validator) add(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validateKeys walks raw YAML with type of boot config, keys are matched with field names case-insensitively
+// as mapstructure does.
+func (v *validator) validateKeys(path string, raw interface{}, typ reflect.Type) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		rawM, ok := raw.(map[interface{}]interface{})
+		if !ok {
+			// type mismatch would be reported by mapstructure
+			return
+		}
+
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < typ.NumField(); i++ {
+			if field := typ.Field(i); field.PkgPath == "" {
+				fields[strings.ToLower(field.Name)] = field.Type
+			}
+		}
+
+		for _, key := range sortedKeys(rawM) {
+			name := fmt.Sprintf("%v", key)
+			fieldType, ok := fields[strings.ToLower(name)]
+			if !ok {
+				v.add(path+"."+name, "unknown key")
+				continue
+			}
+
+			v.validateKeys(path+"."+name, rawM[key], fieldType)
+		}
+	case reflect.Slice, reflect.Array:
+		rawL, ok := raw.([]interface{})
+		if !ok {
+			return
+		}
+
+		for i := range rawL {
+			v.validateKeys(fmt.Sprintf("%s[%d]", path, i), rawL[i], typ.Elem())
+		}
+	case reflect.Map:
+		rawM, ok := raw.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+
+		for _, key := range sortedKeys(rawM) {
+			v.validateKeys(fmt.Sprintf("%s.%v", path, key), rawM[key], typ.Elem())
+		}
+	}
+}
+
+// validateElement validates values of enabled entry.
+func (v *validator) validateElement(path string, element *BootGinElement) {
+	v.validateEntryRef(path+".certEntry", element.CertEntry, rkentry.GlobalAppCtx.GetCertEntry(element.CertEntry) != nil)
+	v.validateEntryRef(path+".loggerEntry", element.LoggerEntry, rkentry.GlobalAppCtx.GetLoggerEntry(element.LoggerEntry) != nil)
+	v.validateEntryRef(path+".eventEntry", element.EventEntry, rkentry.GlobalAppCtx.GetEventEntry(element.EventEntry) != nil)
+	v.validateEntryRef(path+".management.certEntry", element.Management.CertEntry,
+		rkentry.GlobalAppCtx.GetCertEntry(element.Management.CertEntry) != nil)
+
+	if err := ValidateMode(element.Mode); err != nil {
+		v.add(path+".mode", "%v", err)
+	}
+
+	if err := ValidateHosts(element.Hosts...); err != nil {
+		v.add(path+".hosts", "%v", err)
+	}
+
+	if element.TrustedProxies != nil {
+		if _, err := ParseCidrs(*element.TrustedProxies...); err != nil {
+			v.add(path+".trustedProxies", "%v", err)
+		}
+	}
+
+	if _, err := element.TLS.ToOptions(); err != nil {
+		v.add(path+".tls", "%v", err)
+	}
+
+	if _, err := element.Listener.ToOptions(); err != nil {
+		v.add(path+".listener", "%v", err)
+	}
+
+	if _, err := element.MiddlewareReload.ToOptions(); err != nil {
+		v.add(path+".middlewareReload", "%v", err)
+	}
+
+	if _, err := element.Workers.ToOptions(); err != nil {
+		v.add(path+".workers", "%v", err)
+	}
+
+	if _, err := element.ProxyProtocol.ToOptions(); err != nil {
+		v.add(path+".proxyProtocol.trustedCidrs", "%v", err)
+	}
+
+	if _, err := element.Middleware.ToErrorBuilder(); err != nil {
+		v.add(path+".middleware.errorModel", "unknown error model %q, options: %s",
+			element.Middleware.ErrorModel, strings.Join(ListErrorModels(), ", "))
+	}
+
+	v.validateMiddleware(path+".middleware", &element.Middleware)
+
+	groups := make(map[string]int)
+	for i := range element.RouteGroups {
+		group := &element.RouteGroups[i]
+		groupPath := fmt.Sprintf("%s.routeGroups[%d]", path, i)
+
+		if len(strings.TrimSpace(group.Name)) < 1 {
+			v.add(groupPath+".name", "empty name of route group")
+		} else if prev, ok := groups[group.Name]; ok {
+			v.add(groupPath+".name", "duplicate route group %q, already used by %s.routeGroups[%d]", group.Name, path, prev)
+		} else {
+			groups[group.Name] = i
+		}
+
+		if !strings.HasPrefix(group.Path, "/") {
+			v.add(groupPath+".path", "path of route group must start with /")
+		}
+
+		if group.Middleware.Prom.Enabled {
+			v.add(groupPath+".middleware.prom", "prom middleware is not supported in route group")
+		}
+
+		if len(group.Middleware.ErrorModel) > 0 {
+			v.add(groupPath+".middleware.errorModel", "errorModel is not supported in route group")
+		}
+
+		v.validateMiddleware(groupPath+".middleware", &group.Middleware)
+	}
+}
+
+// validateMiddleware validates order and enum values of middlewares.
+func (v *validator) validateMiddleware(path string, boot *BootMiddleware) {
+	if _, err := boot.ResolveOrder(); err != nil {
+		v.add(path+".order", "%v", err)
+	}
+
+	for i := range boot.Custom {
+		if boot.Custom[i].Enabled && GetMiddlewareFactory(boot.Custom[i].Name) == nil {
+			v.add(fmt.Sprintf("%s.custom[%d].name", path, i), "middleware factory %q is not registered", boot.Custom[i].Name)
+		}
+	}
+
+	if len(boot.Gzip.Level) > 0 && !containsFold(gzipLevels, boot.Gzip.Level) {
+		v.add(path+".gzip.level", "invalid level %q, options: %s", boot.Gzip.Level, strings.Join(gzipLevels, ", "))
+	}
+
+	if len(boot.Csrf.CookieSameSite) > 0 && !containsFold(cookieSameSites, boot.Csrf.CookieSameSite) {
+		v.add(path+".csrf.cookieSameSite", "invalid cookieSameSite %q, options: %s",
+			boot.Csrf.CookieSameSite, strings.Join(cookieSameSites, ", "))
+	}
+}
+
+// validateEntryRef reports reference of entry which was not registered.
+func (v *validator) validateEntryRef(path, name string, found bool) {
+	if len(name) > 0 && !found {
+		v.add(path, "entry %q is not registered", name)
+	}
+}
+
+// validatorBind is address bound by entry or management listener of entry.
+type validatorBind struct {
+	path  string
+	host  string
+	port  uint64
+	hosts []string
+	tls   bool
+}
+
+// collides checks whether two addresses bind the same port, empty host and wildcard host collide with any host.
+func (b *validatorBind) collides(other *validatorBind) bool {
+	if b.port != other.port {
+		return false
+	}
+
+	return isWildcardHost(b.host) || isWildcardHost(other.host) || b.host == other.host
+}
+
+// conflict returns reason why two colliding addresses could not be shared, empty if they could be shared by
+// virtual hosting, which requires the same address, hosts on both sides without duplicates and the same TLS setting.
+func (b *validatorBind) conflict(other *validatorBind) string {
+	if len(b.hosts) < 1 || len(other.hosts) < 1 || b.host != other.host {
+		return fmt.Sprintf("port %d collides with %s", b.port, other.path)
+	}
+
+	if b.tls != other.tls {
+		return fmt.Sprintf("TLS must be enabled or disabled on all entries sharing port %d with %s", b.port, other.path)
+	}
+
+	for _, host := range b.hosts {
+		for _, otherHost := range other.hosts {
+			if host == otherHost {
+				return fmt.Sprintf("host %q on port %d is served by %s already", host, b.port, other.path)
+			}
+		}
+	}
+
+	return ""
+}
+
+// isWildcardHost checks whether host listens on all interfaces.
+func isWildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+// containsFold checks whether list contains value case-insensitively.
+func containsFold(list []string, value string) bool {
+	for i := range list {
+		if strings.EqualFold(list[i], value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns keys of YAML map in order of their string forms.
+func sortedKeys(rawM map[interface{}]interface{}) []interface{} {
+	res := make([]interface{}, 0, len(rawM))
+	for k := range rawM {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return fmt.Sprintf("%v", res[i]) < fmt.Sprintf("%v", res[j])
+	})
+
+	return res
+}
@@ -0,0 +1,230 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidateBootGin_HappyCase(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-validate-1
+    port: 1949
+    enabled: true
+    commonService:
+      enabled: true
+    middleware:
+      errorModel: rfc7807
+      ignore: ["/ut"]
+      rateLimit:
+        enabled: true
+      gzip:
+        enabled: true
+        level: BestSpeed
+      csrf:
+        enabled: true
+        cookieSameSite: lax
+    routeGroups:
+      - name: v1
+        path: /v1
+  - name: ut-validate-2
+    port: 2008
+    enabled: true
+    management:
+      port: 2009
+  - name: ut-validate-1
+    port: 1949
+    enabled: false
+`
+	assert.Empty(t, ValidateBootGin([]byte(bootStr)))
+
+	// without gin
+	assert.Empty(t, ValidateBootGin([]byte(`logger: []`)))
+
+	// invalid YAML
+	assert.Len(t, ValidateBootGin([]byte(`gin: [`)), 1)
+}
+
+func TestValidateBootGin_UnknownKeys(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-validate
+    port: 1949
+    enabled: true
+    unknown: true
+    middleware:
+      gzip:
+        enabled: true
+        lvl: bestSpeed
+      custom:
+        - name: ut
+          enabled: true
+          config:
+            anything: true
+`
+	errs := ValidateBootGin([]byte(bootStr))
+	assert.Equal(t, []string{
+		"gin[0].middleware.custom[0].name: middleware factory \"ut\" is not registered",
+		"gin[0].middleware.gzip.lvl: unknown key",
+		"gin[0].unknown: unknown key",
+	}, sortedErrors(errs))
+}
+
+func TestValidateBootGin_Entries(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-validate
+    port: 1949
+    enabled: true
+    management:
+      port: 2008
+  - name: ut-validate
+    port: 2008
+    enabled: true
+  - name: ut-validate-host
+    port: 1949
+    enabled: true
+    server:
+      host: 127.0.0.1
+  - name: ut-validate-unix
+    port: 1949
+    enabled: true
+    listener:
+      type: unix
+      path: /tmp/ut.sock
+`
+	errs := ValidateBootGin([]byte(bootStr))
+	assert.Equal(t, []string{
+		"gin[1].name: duplicate entry name \"ut-validate\", already used by gin[0]",
+		"gin[1].port: port 2008 collides with gin[0].management.port",
+		"gin[2].port: port 1949 collides with gin[0].port",
+	}, sortedErrors(errs))
+}
+
+func TestValidateBootGin_Overrides(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-validate-override-1
+    port: 1949
+    enabled: true
+  - name: ut-validate-override-2
+    port: 1949
+    enabled: true
+  - name: ut-validate-override-3
+    port: 2008
+    enabled: false
+    mode: production
+  - name: ut-validate-override-4
+    port: 2008
+    enabled: true
+`
+	res := ValidateBootGin([]byte(bootStr))
+	assert.Len(t, res, 1)
+	assert.Contains(t, res[0].Error(), "gin[1].port")
+
+	// port collision fixed by override
+	t.Setenv("RK_GIN_1_PORT", "1950")
+	assert.Empty(t, ValidateBootGin([]byte(bootStr)))
+
+	// entry enabled by override is validated, entry disabled by override does not collide
+	t.Setenv("RK_GIN_2_ENABLED", "true")
+	t.Setenv("RK_GIN_3_ENABLED", "false")
+	res = ValidateBootGin([]byte(bootStr))
+	assert.Len(t, res, 1)
+	assert.Contains(t, res[0].Error(), "gin[2].mode: unknown mode \"production\"")
+}
+
+func TestValidateBootGin_Values(t *testing.T) {
+	bootStr := `
+gin:
+  - name: ut-validate
+    port: 1949
+    enabled: true
+    certEntry: ut-validate-cert
+    loggerEntry: ut-validate-logger
+    eventEntry: ut-validate-event
+    mode: production
+    trustedProxies: ["invalid"]
+    workers:
+      jobs:
+        - name: ut-cron
+          cron: "* * *"
+    middleware:
+      errorModel: unknown
+      order: ["unknown"]
+      gzip:
+        level: fastest
+      csrf:
+        cookieSameSite: loose
+    routeGroups:
+      - name: v1
+        path: v1
+        middleware:
+          errorModel: google
+          prom:
+            enabled: true
+      - name: v1
+        path: /v1
+`
+	errs := ValidateBootGin([]byte(bootStr))
+	res := sortedErrors(errs)
+	assert.Len(t, res, 14)
+	assert.Contains(t, res, "gin[0].certEntry: entry \"ut-validate-cert\" is not registered")
+	assert.Contains(t, res, "gin[0].loggerEntry: entry \"ut-validate-logger\" is not registered")
+	assert.Contains(t, res, "gin[0].eventEntry: entry \"ut-validate-event\" is not registered")
+	assert.Contains(t, res, "gin[0].mode: unknown mode \"production\", options: debug, release, test")
+	assert.Contains(t, res, "gin[0].trustedProxies: invalid CIDR \"invalid\"")
+	assert.Contains(t, res, `gin[0].workers: job "ut-cron": invalid cron spec "* * *", expect 5 fields like "*/5 * * * *" or descriptor like @daily`)
+	assert.Contains(t, strings.Join(res, "\n"), "gin[0].middleware.errorModel: unknown error model \"unknown\", options: ")
+	assert.Contains(t, res, "gin[0].middleware.order: unknown middleware \"unknown\" in middleware.order, options: logging, panic, prom, trace, cors, jwt, secure, csrf, gzip, meta, auth, timeout, rateLimit")
+	assert.Contains(t, res, "gin[0].middleware.gzip.level: invalid level \"fastest\", options: noCompression, bestSpeed, bestCompression, defaultCompression, huffmanOnly")
+	assert.Contains(t, res, "gin[0].middleware.csrf.cookieSameSite: invalid cookieSameSite \"loose\", options: default, lax, strict, none")
+	assert.Contains(t, res, "gin[0].routeGroups[0].path: path of route group must start with /")
+	assert.Contains(t, res, "gin[0].routeGroups[0].middleware.errorModel: errorModel is not supported in route group")
+	assert.Contains(t, res, "gin[0].routeGroups[0].middleware.prom: prom middleware is not supported in route group")
+	assert.Contains(t, res, "gin[0].routeGroups[1].name: duplicate route group \"v1\", already used by gin[0].routeGroups[0]")
+
+	// decode error
+	errs = ValidateBootGin([]byte(`
+gin:
+  - name: ut-validate
+    port: invalid
+    enabled: true
+`))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "gin", errs[0].(*ValidationError).Path)
+}
+
+func TestRegisterGinEntryYAML_Invalid(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterGinEntryYAML([]byte(`
+gin:
+  - name: ut-validate
+    port: 1949
+    enabled: true
+    middleware:
+      gzip:
+        level: fastest
+`))
+}
+
+func sortedErrors(errs []error) []string {
+	res := make([]string, 0, len(errs))
+	for i := range errs {
+		res = append(res, errs[i].Error())
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// virtualHosts holds entries which serve hosts on the same address.
+var virtualHosts = &virtualHostRegistry{
+	groups: make(map[string]*virtualHost),
+}
+
+// WithHosts provide host names served by entry, entries with hosts could share the same address.
+//
+// Requests are dispatched to Router of entry by Host header, and server certificate is selected by TLS SNI from
+// CertEntry of entry. Host could be exact name like api.example.com, wildcard like *.example.com, or * which serves
+// hosts not matched by any entry. Server options, like timeouts, HTTP/2 and PROXY protocol, of the entry bootstrapped
+// first on the address are used by the shared server.
+func WithHosts(hosts ...string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.hosts = make([]string, 0, len(hosts))
+		for i := range hosts {
+			entry.hosts = append(entry.hosts, normalizeHost(hosts[i]))
+		}
+	}
+}
+
+// GetHosts returns host names served by entry, empty if entry serves any host on its own listener.
+func (entry *GinEntry) GetHosts() []string {
+	return append([]string{}, entry.hosts...)
+}
+
+// ValidateHosts checks host names of virtual hosting.
+func ValidateHosts(hosts ...string) error {
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		host = normalizeHost(host)
+		pattern := strings.TrimPrefix(host, "*.")
+
+		switch {
+		case len(host) < 1:
+			return errors.New("empty host")
+		case host == "*":
+		case strings.ContainsAny(pattern, "*/:") || len(pattern) < 1:
+			return fmt.Errorf("invalid host %q, expect name like api.example.com, *.example.com or *", host)
+		}
+
+		if seen[host] {
+			return fmt.Errorf("duplicate host %q", host)
+		}
+		seen[host] = true
+	}
+
+	return nil
+}
+
+// normalizeHost lowercases host and removes port and trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(host, ".")
+}
+
+// joinVirtualHost adds entry into virtual host on address of entry.
+//
+// The first entry joined binds listener and serves it with a handler which dispatches requests to joined entries.
+// Nil listener would be returned if listener was bound by another entry.
+func (entry *GinEntry) joinVirtualHost() (net.Listener, error) {
+	if err := ValidateHosts(entry.hosts...); err != nil {
+		return nil, fmt.Errorf("gin entry %q: %v", entry.entryName, err)
+	}
+
+	virtualHosts.lock.Lock()
+	defer virtualHosts.lock.Unlock()
+
+	v, ok := virtualHosts.groups[entry.Server.Addr]
+	if !ok {
+		v = &virtualHost{addr: entry.Server.Addr}
+	}
+
+	if err := v.add(entry); err != nil {
+		return nil, err
+	}
+	entry.virtualHost = v
+
+	if v.owner != nil {
+		entry.listener = v.owner.listener
+		return nil, nil
+	}
+
+	// serve shared listener with dispatcher
+	entry.Server.Handler = entry.wrapH2c(v)
+	if entry.IsTlsEnabled() {
+		entry.Server.TLSConfig = &tls.Config{
+			GetCertificate:     v.getCertificate,
+			GetConfigForClient: v.getConfigForClient,
+		}
+	}
+
+	ln, err := entry.bind()
+	if err != nil {
+		v.remove(entry)
+		entry.virtualHost = nil
+		return nil, err
+	}
+
+	v.owner = entry
+	virtualHosts.groups[v.addr] = v
+
+	return ln, nil
+}
+
+// leaveVirtualHost removes entry from virtual host, and returns server which should be shut down if entry is the
+// last one on the address, nil otherwise.
+func (entry *GinEntry) leaveVirtualHost() *http.Server {
+	v := entry.virtualHost
+	if v == nil {
+		return nil
+	}
+
+	virtualHosts.lock.Lock()
+	defer virtualHosts.lock.Unlock()
+
+	entry.virtualHost = nil
+	if v.remove(entry) > 0 {
+		entry.listener = nil
+		return nil
+	}
+
+	delete(virtualHosts.groups, v.addr)
+	if v.owner == nil {
+		return nil
+	}
+
+	return v.owner.Server
+}
+
+// virtualHostRegistry holds virtual hosts by address.
+type virtualHostRegistry struct {
+	lock   sync.Mutex
+	groups map[string]*virtualHost
+}
+
+// virtualHost dispatches requests and TLS handshakes on shared listener to entries by host.
+type virtualHost struct {
+	addr    string
+	owner   *GinEntry
+	lock    sync.RWMutex
+	members []*virtualHostMember
+}
+
+// virtualHostMember is entry joined virtual host.
+type virtualHostMember struct {
+	entry     *GinEntry
+	handler   http.Handler
+	tlsConfig *tls.Config
+}
+
+// add entry into virtual host, hosts served by more than one entry and mixed TLS settings are rejected.
+func (v *virtualHost) add(entry *GinEntry) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for _, member := range v.members {
+		if member.entry.IsTlsEnabled() != entry.IsTlsEnabled() {
+			return fmt.Errorf("gin entry %q: TLS must be enabled or disabled on all entries on %s, conflicts with gin entry %q",
+				entry.entryName, v.addr, member.entry.entryName)
+		}
+
+		for _, host := range entry.hosts {
+			for _, other := range member.entry.hosts {
+				if host == other {
+					return fmt.Errorf("gin entry %q: host %q on %s is served by gin entry %q already",
+						entry.entryName, host, v.addr, member.entry.entryName)
+				}
+			}
+		}
+	}
+
+	member := &virtualHostMember{
+		entry:   entry,
+		handler: entry.trackInFlight(entry.Router),
+	}
+
+	// TLS config of entry was created in listen(), HTTP/2 is negotiated unless entry provided protocols
+	if entry.IsTlsEnabled() {
+		member.tlsConfig = entry.Server.TLSConfig.Clone()
+		if len(member.tlsConfig.NextProtos) < 1 {
+			member.tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		}
+	}
+
+	v.members = append(v.members, member)
+
+	return nil
+}
+
+// remove entry from virtual host and returns number of remaining entries.
+func (v *virtualHost) remove(entry *GinEntry) int {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for i := range v.members {
+		if v.members[i].entry == entry {
+			v.members = append(v.members[:i], v.members[i+1:]...)
+			break
+		}
+	}
+
+	return len(v.members)
+}
+
+// match returns entry serving host, exact name wins over longest wildcard name, and * serves the rest.
+func (v *virtualHost) match(host string) *virtualHostMember {
+	host = normalizeHost(host)
+
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	var res *virtualHostMember
+	matched := -1
+
+	for _, member := range v.members {
+		for _, pattern := range member.entry.hosts {
+			switch {
+			case pattern == host:
+				return member
+			case pattern == "*":
+				if matched < 0 {
+					res, matched = member, 0
+				}
+			case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) && len(pattern) > matched:
+				res, matched = member, len(pattern)
+			}
+		}
+	}
+
+	return res
+}
+
+// ServeHTTP dispatches request to Router of entry by Host header.
+//
+// TLS requests must be sent to the entry whose TLS config was selected by SNI, otherwise client certificate
+// verification of an entry could be bypassed by handshaking with SNI of another entry, 421 would be returned.
+func (v *virtualHost) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	member := v.match(req.Host)
+	if member == nil {
+		v.owner.writeError(writer, http.StatusMisdirectedRequest, fmt.Sprintf("No gin entry serves host %q", req.Host))
+		return
+	}
+
+	if req.TLS != nil && v.matchServerName(req.TLS.ServerName) != member {
+		v.owner.writeError(writer, http.StatusMisdirectedRequest,
+			fmt.Sprintf("Host %q does not match TLS server name %q", req.Host, req.TLS.ServerName))
+		return
+	}
+
+	member.handler.ServeHTTP(writer, req)
+}
+
+// matchServerName returns entry serving SNI, the first entry joined would be used if not matched.
+func (v *virtualHost) matchServerName(serverName string) *virtualHostMember {
+	if member := v.match(serverName); member != nil {
+		return member
+	}
+
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	if len(v.members) > 0 {
+		return v.members[0]
+	}
+
+	return nil
+}
+
+// getConfigForClient returns TLS config of entry by SNI, the first entry joined would be used if not matched.
+func (v *virtualHost) getConfigForClient(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+	serverName := ""
+	if hello != nil {
+		serverName = hello.ServerName
+	}
+
+	member := v.matchServerName(serverName)
+	if member == nil {
+		return nil, fmt.Errorf("no gin entry serves host %q", serverName)
+	}
+
+	return member.tlsConfig, nil
+}
+
+// getCertificate returns certificate of entry by SNI, it is used if TLS config of entry was not selected.
+func (v *virtualHost) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	conf, err := v.getConfigForClient(hello)
+	if err != nil {
+		return nil, err
+	}
+
+	return conf.GetCertificate(hello)
+}
@@ -0,0 +1,266 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestValidateHosts(t *testing.T) {
+	assert.Nil(t, ValidateHosts())
+	assert.Nil(t, ValidateHosts("api.example.com", "*.example.com", "*", "ADMIN.example.com.:8080"))
+
+	assert.NotNil(t, ValidateHosts(""))
+	assert.NotNil(t, ValidateHosts("*.*.example.com"))
+	assert.NotNil(t, ValidateHosts("api.example.com/v1"))
+	assert.NotNil(t, ValidateHosts("api.*.com"))
+	assert.NotNil(t, ValidateHosts("api.example.com", "API.example.com"))
+}
+
+func TestVirtualHost_Match(t *testing.T) {
+	api := &virtualHostMember{entry: RegisterGinEntry(WithName("ut-vhost-match-api"), WithHosts("api.example.com"))}
+	wildcard := &virtualHostMember{entry: RegisterGinEntry(WithName("ut-vhost-match-wildcard"), WithHosts("*.example.com"))}
+	nested := &virtualHostMember{entry: RegisterGinEntry(WithName("ut-vhost-match-nested"), WithHosts("*.v1.example.com"))}
+	fallback := &virtualHostMember{entry: RegisterGinEntry(WithName("ut-vhost-match-any"), WithHosts("*"))}
+	for _, member := range []*virtualHostMember{api, wildcard, nested, fallback} {
+		defer rkentry.GlobalAppCtx.RemoveEntry(member.entry)
+	}
+
+	v := &virtualHost{members: []*virtualHostMember{fallback, wildcard, nested, api}}
+	assert.Equal(t, api, v.match("API.example.com:8080"))
+	assert.Equal(t, wildcard, v.match("admin.example.com"))
+	assert.Equal(t, nested, v.match("admin.v1.example.com"))
+	assert.Equal(t, fallback, v.match("example.com"))
+	assert.Equal(t, fallback, v.match(""))
+
+	v = &virtualHost{members: []*virtualHostMember{api}}
+	assert.Nil(t, v.match("admin.example.com"))
+}
+
+func TestGinEntry_VirtualHost(t *testing.T) {
+	api := RegisterGinEntry(WithName("ut-vhost-api"), WithHost("127.0.0.1"), WithPort(0), WithHosts("api.example.com"))
+	admin := RegisterGinEntry(WithName("ut-vhost-admin"), WithHost("127.0.0.1"), WithPort(0), WithHosts("*.admin.example.com"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(api)
+	defer rkentry.GlobalAppCtx.RemoveEntry(admin)
+
+	api.Router.GET("/ut", func(ctx *gin.Context) { ctx.String(http.StatusOK, "api") })
+	admin.Router.GET("/ut", func(ctx *gin.Context) { ctx.String(http.StatusOK, "admin") })
+
+	assert.Nil(t, api.BootstrapE(context.TODO()))
+	assert.Nil(t, admin.BootstrapE(context.TODO()))
+	assert.Equal(t, api.ListenAddr(), admin.ListenAddr())
+
+	// duplicate host
+	dup := RegisterGinEntry(WithName("ut-vhost-dup"), WithHost("127.0.0.1"), WithPort(0), WithHosts("API.example.com"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(dup)
+	assert.NotNil(t, dup.BootstrapE(context.TODO()))
+
+	addr := api.ListenAddr().String()
+	code, body := vhostRequest(t, addr, "api.example.com")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "api", body)
+
+	code, body = vhostRequest(t, addr, "eu.admin.example.com")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "admin", body)
+
+	code, _ = vhostRequest(t, addr, "unknown.example.com")
+	assert.Equal(t, http.StatusMisdirectedRequest, code)
+
+	// entry bound listener leaves, other entry keeps serving
+	api.Interrupt(context.TODO())
+	code, _ = vhostRequest(t, addr, "api.example.com")
+	assert.Equal(t, http.StatusMisdirectedRequest, code)
+	code, _ = vhostRequest(t, addr, "eu.admin.example.com")
+	assert.Equal(t, http.StatusOK, code)
+
+	// the last entry stops server
+	admin.Interrupt(context.TODO())
+	_, err := http.Get("http://" + addr + "/ut")
+	assert.NotNil(t, err)
+	assert.Empty(t, virtualHosts.groups)
+}
+
+func TestGinEntry_VirtualHostTls(t *testing.T) {
+	ca, caKey := generateCA()
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	entries := make([]*GinEntry, 0)
+	for _, host := range []string{"api.example.com", "admin.example.com"} {
+		cert := generateLeaf(ca, caKey, host, nil)
+		certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+			Cert: []*rkentry.BootCertE{{Name: "ut-cert-vhost-" + host}},
+		})[0]
+		certEntry.Certificate = &cert
+		defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+		entry := RegisterGinEntry(
+			WithName("ut-vhost-tls-"+host), WithHost("127.0.0.1"), WithPort(0), WithHosts(host), WithCertEntry(certEntry))
+		entry.Router.GET("/ut", func(ctx *gin.Context) { ctx.String(http.StatusOK, ctx.Request.Proto) })
+		defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+		entries = append(entries, entry)
+	}
+
+	// TLS must be enabled on all entries sharing address
+	plain := RegisterGinEntry(WithName("ut-vhost-tls-plain"), WithHost("127.0.0.1"), WithPort(0), WithHosts("plain.example.com"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(plain)
+
+	for _, entry := range entries {
+		assert.Nil(t, entry.BootstrapE(context.TODO()))
+		defer entry.Interrupt(context.TODO())
+	}
+	assert.NotNil(t, plain.BootstrapE(context.TODO()))
+
+	addr := entries[0].ListenAddr().String()
+	for _, host := range []string{"api.example.com", "admin.example.com"} {
+		// certificate selected by SNI
+		conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: host, RootCAs: pool, NextProtos: []string{"h2"}})
+		if assert.Nil(t, err) {
+			state := conn.ConnectionState()
+			assert.Equal(t, host, state.PeerCertificates[0].Subject.CommonName)
+			assert.Equal(t, "h2", state.NegotiatedProtocol)
+			conn.Close()
+		}
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+				DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, network, addr)
+				},
+			},
+		}
+		resp, err := client.Get("https://" + host + "/ut")
+		if assert.Nil(t, err) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			resp.Body.Close()
+		}
+	}
+}
+
+func TestGinEntry_VirtualHostTlsMismatch(t *testing.T) {
+	ca, caKey := generateCA()
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	register := func(host string, opts ...GinEntryOption) *GinEntry {
+		cert := generateLeaf(ca, caKey, host, nil)
+		certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+			Cert: []*rkentry.BootCertE{{Name: "ut-cert-vhost-mismatch-" + host}},
+		})[0]
+		certEntry.Certificate = &cert
+		certEntry.RootCA = ca
+		t.Cleanup(func() { rkentry.GlobalAppCtx.RemoveEntry(certEntry) })
+
+		opts = append(opts,
+			WithName("ut-vhost-mismatch-"+host), WithHost("127.0.0.1"), WithPort(0), WithHosts(host), WithCertEntry(certEntry))
+		entry := RegisterGinEntry(opts...)
+		entry.Router.GET("/ut", func(ctx *gin.Context) { ctx.String(http.StatusOK, host) })
+		t.Cleanup(func() { rkentry.GlobalAppCtx.RemoveEntry(entry) })
+
+		return entry
+	}
+
+	public := register("public.example.com")
+	admin := register("admin.example.com", WithClientAuth(tls.RequireAndVerifyClientCert))
+	assert.Nil(t, public.BootstrapE(context.TODO()))
+	defer public.Interrupt(context.TODO())
+	assert.Nil(t, admin.BootstrapE(context.TODO()))
+	defer admin.Interrupt(context.TODO())
+
+	addr := public.ListenAddr().String()
+	request := func(serverName, host string) int {
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool, ServerName: serverName},
+				DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, network, addr)
+				},
+			},
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "https://"+serverName+"/ut", nil)
+		req.Host = host
+		resp, err := client.Do(req)
+		if !assert.Nil(t, err) {
+			return 0
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	// handshake without client certificate with SNI of public entry, Host header of admin entry
+	assert.Equal(t, http.StatusMisdirectedRequest, request("public.example.com", "admin.example.com"))
+	assert.Equal(t, http.StatusOK, request("public.example.com", "public.example.com"))
+}
+
+func TestValidateBootGin_VirtualHost(t *testing.T) {
+	errs := ValidateBootGin([]byte(`
+gin:
+  - name: ut-vhost-api
+    port: 8096
+    enabled: true
+    hosts: ["api.example.com"]
+  - name: ut-vhost-admin
+    port: 8096
+    enabled: true
+    hosts: ["admin.example.com", "*"]
+`))
+	assert.Empty(t, errs)
+
+	errs = ValidateBootGin([]byte(`
+gin:
+  - name: ut-vhost-api
+    port: 8096
+    enabled: true
+    hosts: ["api.example.com"]
+  - name: ut-vhost-admin
+    port: 8096
+    enabled: true
+    hosts: ["API.example.com"]
+  - name: ut-vhost-any
+    port: 8096
+    enabled: true
+  - name: ut-vhost-invalid
+    port: 8097
+    enabled: true
+    hosts: ["*.*"]
+`))
+	assert.Equal(t, []string{
+		`gin[1].port: host "api.example.com" on port 8096 is served by gin[0].port already`,
+		"gin[2].port: port 8096 collides with gin[0].port",
+		`gin[3].hosts: invalid host "*.*", expect name like api.example.com, *.example.com or *`,
+	}, sortedErrors(errs))
+}
+
+func vhostRequest(t *testing.T, addr, host string) (int, string) {
+	req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/ut", nil)
+	req.Host = host
+
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
@@ -0,0 +1,366 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWorkerDrainTimeout max duration of waiting workers to return after cancelled
+	defaultWorkerDrainTimeout = 5 * time.Second
+)
+
+// BootWorkers bootstrap config of background workers registered with GinEntry.AddWorker() and GinEntry.AddCron().
+//
+// Functions of workers are registered in code, jobs override schedule of workers with the same name.
+type BootWorkers struct {
+	DrainTimeoutMs int             `yaml:"drainTimeoutMs" json:"drainTimeoutMs"`
+	Jobs           []BootWorkerJob `yaml:"jobs" json:"jobs"`
+}
+
+// BootWorkerJob overrides worker with the same name.
+//
+// Worker would run on Cron if provided, and would not be started if Disabled.
+type BootWorkerJob struct {
+	Name     string `yaml:"name" json:"name"`
+	Cron     string `yaml:"cron" json:"cron"`
+	Disabled bool   `yaml:"disabled" json:"disabled"`
+}
+
+// ToOptions validates config and converts it into GinEntryOption.
+func (boot *BootWorkers) ToOptions() ([]GinEntryOption, error) {
+	if boot.DrainTimeoutMs < 0 {
+		return nil, fmt.Errorf("negative drainTimeoutMs %d of workers", boot.DrainTimeoutMs)
+	}
+
+	opts := []GinEntryOption{
+		WithWorkerDrainTimeout(time.Duration(boot.DrainTimeoutMs) * time.Millisecond),
+	}
+
+	names := make(map[string]bool)
+	disabled := make([]string, 0)
+	for i := range boot.Jobs {
+		job := &boot.Jobs[i]
+		if len(strings.TrimSpace(job.Name)) < 1 {
+			return nil, fmt.Errorf("empty name of jobs[%d]", i)
+		}
+
+		if names[job.Name] {
+			return nil, fmt.Errorf("duplicate job %q", job.Name)
+		}
+		names[job.Name] = true
+
+		if job.Disabled {
+			disabled = append(disabled, job.Name)
+			continue
+		}
+
+		if len(job.Cron) > 0 {
+			if _, err := parseCronSpec(job.Cron); err != nil {
+				return nil, fmt.Errorf("job %q: %v", job.Name, err)
+			}
+			opts = append(opts, WithWorkerCron(job.Name, job.Cron))
+		}
+	}
+
+	if len(disabled) > 0 {
+		opts = append(opts, WithDisabledWorkers(disabled...))
+	}
+
+	return opts, nil
+}
+
+// WithWorkerDrainTimeout provide max duration of waiting workers to return after they are cancelled on Interrupt.
+func WithWorkerDrainTimeout(timeout time.Duration) GinEntryOption {
+	return func(entry *GinEntry) {
+		if timeout > 0 {
+			entry.workerGroup.drainTimeout = timeout
+		}
+	}
+}
+
+// WithWorkerCron run worker with name on cron spec, it overrides spec provided to GinEntry.AddCron().
+func WithWorkerCron(name, spec string) GinEntryOption {
+	return func(entry *GinEntry) {
+		entry.workerGroup.specs[name] = spec
+	}
+}
+
+// WithDisabledWorkers skip workers with names.
+func WithDisabledWorkers(names ...string) GinEntryOption {
+	return func(entry *GinEntry) {
+		for i := range names {
+			entry.workerGroup.disabled[names[i]] = true
+		}
+	}
+}
+
+// WorkerFunc is called by worker, ctx is cancelled on Interrupt.
+type WorkerFunc func(ctx context.Context) error
+
+// AddWorker registers background worker which is started on Bootstrap and cancelled on Interrupt.
+//
+// Worker is called once, or on cron spec of jobs in boot config with the same name.
+// Worker would be started immediately if entry was bootstrapped already.
+func (entry *GinEntry) AddWorker(name string, fn WorkerFunc) error {
+	return entry.addWorker(name, "", fn)
+}
+
+// AddCron registers background worker which is called on cron spec, like "*/5 * * * *", @hourly or @every 30s.
+//
+// Runs of the same worker never overlap, activations missed while worker is running are skipped.
+func (entry *GinEntry) AddCron(name, spec string, fn WorkerFunc) error {
+	if len(spec) < 1 {
+		return fmt.Errorf("empty cron spec of worker %q", name)
+	}
+
+	return entry.addWorker(name, spec, fn)
+}
+
+// ListWorkers returns names of workers in order of registration.
+func (entry *GinEntry) ListWorkers() []string {
+	g := entry.workerGroup
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	res := make([]string, 0, len(g.workers))
+	for i := range g.workers {
+		res = append(res, g.workers[i].name)
+	}
+
+	return res
+}
+
+// addWorker creates worker with schedule resolved from spec and boot config.
+func (entry *GinEntry) addWorker(name, spec string, fn WorkerFunc) error {
+	if len(strings.TrimSpace(name)) < 1 {
+		return errors.New("empty name of worker")
+	}
+
+	if fn == nil {
+		return fmt.Errorf("nil function of worker %q", name)
+	}
+
+	g := entry.workerGroup
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for i := range g.workers {
+		if g.workers[i].name == name {
+			return fmt.Errorf("duplicate worker %q of gin entry %q", name, entry.entryName)
+		}
+	}
+
+	if g.disabled[name] {
+		entry.LoggerEntry.Info("Worker is disabled.", zap.String("entryName", entry.entryName), zap.String("worker", name))
+		return nil
+	}
+
+	if override, ok := g.specs[name]; ok {
+		spec = override
+	}
+
+	w := &worker{name: name, spec: spec, fn: fn}
+	if len(spec) > 0 {
+		schedule, err := parseCronSpec(spec)
+		if err != nil {
+			return fmt.Errorf("worker %q of gin entry %q: %v", name, entry.entryName, err)
+		}
+		w.schedule = schedule
+	}
+
+	g.workers = append(g.workers, w)
+
+	// started already
+	if g.ctx != nil && g.ctx.Err() == nil {
+		entry.startWorker(g.ctx, w)
+	}
+
+	return nil
+}
+
+// startWorkers registers metrics and starts workers.
+func (entry *GinEntry) startWorkers() {
+	g := entry.workerGroup
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.ctx != nil && g.ctx.Err() == nil {
+		return
+	}
+
+	g.runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rk",
+		Subsystem: "gin",
+		Name:      "worker_runs_total",
+		Help:      "Number of worker runs by result, success or failure.",
+	}, []string{"entryName", "worker", "result"})
+	g.runs, _ = entry.registerCollector(g.runs).(*prometheus.CounterVec)
+
+	g.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rk",
+		Subsystem: "gin",
+		Name:      "worker_run_duration_seconds",
+		Help:      "Duration of worker runs in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, []string{"entryName", "worker"})
+	g.duration, _ = entry.registerCollector(g.duration).(*prometheus.HistogramVec)
+
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	for i := range g.workers {
+		entry.startWorker(g.ctx, g.workers[i])
+	}
+}
+
+// stopWorkers cancels workers and waits for them to return within drain timeout.
+func (entry *GinEntry) stopWorkers(ctx context.Context) error {
+	g := entry.workerGroup
+	g.lock.Lock()
+	cancel := g.cancel
+	g.cancel = nil
+	g.lock.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wait.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(g.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("workers of gin entry %q did not return within drain timeout %s", entry.entryName, g.drainTimeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startWorker runs worker in background, it must be called with lock of worker group held.
+func (entry *GinEntry) startWorker(ctx context.Context, w *worker) {
+	g := entry.workerGroup
+	g.wait.Add(1)
+
+	go func() {
+		defer g.wait.Done()
+
+		if w.schedule == nil {
+			entry.runWorker(ctx, w)
+			return
+		}
+
+		for {
+			now := time.Now()
+			next := w.schedule.next(now)
+			if next.IsZero() {
+				return
+			}
+
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				entry.runWorker(ctx, w)
+			}
+		}
+	}()
+}
+
+// runWorker calls worker once, records result in event, logger and metrics.
+//
+// Worker returned context.Canceled after Interrupt is treated as success.
+func (entry *GinEntry) runWorker(ctx context.Context, w *worker) {
+	event := entry.EventEntry.Start(
+		"RunWorker",
+		rkquery.WithEntryName(entry.GetName()),
+		rkquery.WithEntryType(entry.GetType()))
+	event.AddPayloads(zap.String("worker", w.name))
+	if len(w.spec) > 0 {
+		event.AddPayloads(zap.String("cron", w.spec))
+	}
+
+	logger := entry.LoggerEntry.With(
+		zap.String("eventId", event.GetEventId()),
+		zap.String("entryName", entry.entryName),
+		zap.String("worker", w.name))
+
+	start := time.Now()
+	err := callRecovered(ctx, w.fn)
+	elapsed := time.Since(start)
+
+	if err != nil && errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		err = nil
+	}
+
+	g := entry.workerGroup
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	g.runs.WithLabelValues(entry.entryName, w.name, result).Inc()
+	g.duration.WithLabelValues(entry.entryName, w.name).Observe(elapsed.Seconds())
+
+	if err != nil {
+		event.AddErr(err)
+		logger.Warn("Error occurs while running worker.", zap.Duration("elapsed", elapsed), zap.Error(err))
+		entry.EventEntry.FinishWithCond(event, false)
+		return
+	}
+
+	logger.Info("Worker finished.", zap.Duration("elapsed", elapsed))
+	entry.EventEntry.Finish(event)
+}
+
+// workerGroup holds workers of entry.
+type workerGroup struct {
+	lock         sync.Mutex
+	workers      []*worker
+	drainTimeout time.Duration
+	specs        map[string]string
+	disabled     map[string]bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wait         sync.WaitGroup
+	runs         *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+}
+
+// newWorkerGroup creates workerGroup with default drain timeout.
+func newWorkerGroup() *workerGroup {
+	return &workerGroup{
+		workers:      make([]*worker, 0),
+		drainTimeout: defaultWorkerDrainTimeout,
+		specs:        make(map[string]string),
+		disabled:     make(map[string]bool),
+	}
+}
+
+// worker is function called once or on cron schedule.
+type worker struct {
+	name     string
+	spec     string
+	schedule cronSchedule
+	fn       WorkerFunc
+}
@@ -0,0 +1,163 @@
+//go:build !race
+// +build !race
+
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgin
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBootWorkers_ToOptions(t *testing.T) {
+	opts, err := (&BootWorkers{}).ToOptions()
+	assert.Nil(t, err)
+	assert.Len(t, opts, 1)
+
+	_, err = (&BootWorkers{DrainTimeoutMs: -1}).ToOptions()
+	assert.NotNil(t, err)
+
+	_, err = (&BootWorkers{Jobs: []BootWorkerJob{{Cron: "@hourly"}}}).ToOptions()
+	assert.NotNil(t, err)
+
+	_, err = (&BootWorkers{Jobs: []BootWorkerJob{{Name: "ut"}, {Name: "ut"}}}).ToOptions()
+	assert.NotNil(t, err)
+
+	_, err = (&BootWorkers{Jobs: []BootWorkerJob{{Name: "ut", Cron: "invalid"}}}).ToOptions()
+	assert.NotNil(t, err)
+
+	opts, err = (&BootWorkers{
+		DrainTimeoutMs: 10,
+		Jobs: []BootWorkerJob{
+			{Name: "ut-cron", Cron: "@every 1s"},
+			{Name: "ut-disabled", Disabled: true},
+		},
+	}).ToOptions()
+	assert.Nil(t, err)
+
+	entry := RegisterGinEntry(append(opts, WithName("ut-worker-opts"))...)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Equal(t, 10*time.Millisecond, entry.workerGroup.drainTimeout)
+	assert.Equal(t, "@every 1s", entry.workerGroup.specs["ut-cron"])
+	assert.True(t, entry.workerGroup.disabled["ut-disabled"])
+}
+
+func TestGinEntry_AddWorker(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-worker-add"), WithWorkerCron("ut-yaml-cron", "@every 1m"),
+		WithDisabledWorkers("ut-disabled"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	noop := func(ctx context.Context) error { return nil }
+
+	assert.Nil(t, entry.AddWorker("ut-worker", noop))
+	assert.Nil(t, entry.AddCron("ut-cron", "@hourly", noop))
+	assert.Nil(t, entry.AddWorker("ut-yaml-cron", noop))
+	assert.Nil(t, entry.AddWorker("ut-disabled", noop))
+
+	assert.NotNil(t, entry.AddWorker("", noop))
+	assert.NotNil(t, entry.AddWorker("ut-nil", nil))
+	assert.NotNil(t, entry.AddWorker("ut-worker", noop))
+	assert.NotNil(t, entry.AddCron("ut-empty-spec", "", noop))
+	assert.NotNil(t, entry.AddCron("ut-invalid-spec", "invalid", noop))
+
+	assert.Equal(t, []string{"ut-worker", "ut-cron", "ut-yaml-cron"}, entry.ListWorkers())
+	// cron spec from boot config
+	assert.Equal(t, "@every 1m", entry.workerGroup.workers[2].spec)
+	assert.NotNil(t, entry.workerGroup.workers[2].schedule)
+}
+
+func TestGinEntry_Workers(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-worker"), WithHost("127.0.0.1"), WithPort(0))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	var cronRuns, failedRuns int32
+	assert.Nil(t, entry.AddCron("ut-cron", "@every 10ms", func(ctx context.Context) error {
+		atomic.AddInt32(&cronRuns, 1)
+		return nil
+	}))
+	assert.Nil(t, entry.AddCron("ut-failed", "@every 10ms", func(ctx context.Context) error {
+		atomic.AddInt32(&failedRuns, 1)
+		return errors.New("ut-error")
+	}))
+
+	// long running worker returns once cancelled
+	stopped := make(chan struct{})
+	assert.Nil(t, entry.AddWorker("ut-loop", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	}))
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+	// worker added after bootstrap is started immediately
+	started := make(chan struct{})
+	assert.Nil(t, entry.AddWorker("ut-late", func(ctx context.Context) error {
+		close(started)
+		return nil
+	}))
+	<-started
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&cronRuns) >= 2 && atomic.LoadInt32(&failedRuns) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	entry.Interrupt(context.TODO())
+	<-stopped
+
+	// cancelled worker is not a failure
+	runs := entry.workerGroup.runs
+	assert.Equal(t, float64(1), testutil.ToFloat64(runs.WithLabelValues("ut-worker", "ut-loop", "success")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(runs.WithLabelValues("ut-worker", "ut-loop", "failure")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(runs.WithLabelValues("ut-worker", "ut-failed", "success")))
+	assert.True(t, testutil.ToFloat64(runs.WithLabelValues("ut-worker", "ut-failed", "failure")) >= 2)
+	assert.True(t, testutil.ToFloat64(runs.WithLabelValues("ut-worker", "ut-cron", "success")) >= 2)
+
+	// no more runs after interrupted
+	count := atomic.LoadInt32(&cronRuns)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, atomic.LoadInt32(&cronRuns))
+
+	// not started after interrupted
+	assert.Nil(t, entry.AddWorker("ut-after-interrupt", func(ctx context.Context) error {
+		panic("should not be called")
+	}))
+}
+
+func TestGinEntry_StopWorkers(t *testing.T) {
+	entry := RegisterGinEntry(WithName("ut-worker-stop"), WithWorkerDrainTimeout(10*time.Millisecond))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	// not started
+	assert.Nil(t, entry.stopWorkers(context.TODO()))
+
+	release := make(chan struct{})
+	defer close(release)
+	assert.Nil(t, entry.AddWorker("ut-stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+	assert.Nil(t, entry.AddWorker("ut-panic", func(ctx context.Context) error {
+		panic("ut-panic")
+	}))
+
+	entry.startWorkers()
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(entry.workerGroup.runs.WithLabelValues("ut-worker-stop", "ut-panic", "failure")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err := entry.stopWorkers(context.TODO())
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "did not return within drain timeout 10ms")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// rk-gin-schema prints JSON Schema of gin boot config, so that IDEs and CI could validate boot.yaml before deployment.
+//
+// Usage:
+//
+//	go run github.com/rookie-ninja/rk-gin/v2/cmd/rk-gin-schema -o boot.schema.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/rookie-ninja/rk-gin/v2/boot"
+	"os"
+)
+
+func main() {
+	output := flag.String("o", "", "output file, STDOUT would be used if missing")
+	flag.Parse()
+
+	bytes, err := json.MarshalIndent(rkgin.NewBootGinSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	bytes = append(bytes, '\n')
+
+	if len(*output) < 1 {
+		os.Stdout.Write(bytes)
+		return
+	}
+
+	if err := os.WriteFile(*output, bytes, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
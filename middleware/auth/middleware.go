@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 )
 
 // Middleware validate bellow authorization.
@@ -31,7 +32,7 @@ func Middleware(opts ...rkmidauth.Option) gin.HandlerFunc {
 			for k, v := range beforeCtx.Output.HeadersToReturn {
 				ctx.Writer.Header().Set(k, v)
 			}
-			ctx.AbortWithStatusJSON(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkginctx.AbortWithError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
@@ -7,8 +7,10 @@ package rkginauth
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/rookie-ninja/rk-entry/v2/error"
 	rkmid "github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -36,7 +38,16 @@ func TestInterceptor(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, ctx.Writer.Status())
 	assert.Equal(t, "value", ctx.Writer.Header().Get("key"))
 
-	// case 2: happy case
+	// case 2: with error builder of entry
+	writer := httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(writer)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	rkginctx.SetErrorBuilder(ctx, rkerror.NewErrorBuilderAMZN())
+	inter(ctx)
+	assert.Equal(t, http.StatusUnauthorized, ctx.Writer.Status())
+	assert.Contains(t, writer.Body.String(), `"response"`)
+
+	// case 3: happy case
 	beforeCtx.Output.ErrResp = nil
 	ctx = newCtx()
 	inter(ctx)
@@ -8,9 +8,11 @@ package rkginctx
 
 import (
 	"context"
+	"crypto/x509"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/rookie-ninja/rk-entry/v2/cursor"
+	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-logger"
 	"github.com/rookie-ninja/rk-query"
@@ -21,6 +23,11 @@ import (
 	"net/http"
 )
 
+const (
+	// errorBuilderKey key of error builder of entry in gin.Context
+	errorBuilderKey = "rkErrorBuilder"
+)
+
 var (
 	noopTracerProvider = trace.NewNoopTracerProvider()
 	noopEvent          = rkquery.NewEventFactory().CreateEventNoop()
@@ -261,3 +268,156 @@ func GetCsrfToken(ctx *gin.Context) string {
 
 	return ""
 }
+
+// GetClientIP return IP of client resolved by gin.Engine.
+//
+// X-Forwarded-For and headers configured with RemoteIPHeaders are only honored if request comes from trusted proxies,
+// the header of TrustedPlatform is honored first if configured.
+func GetClientIP(ctx *gin.Context) string {
+	if ctx == nil || ctx.Request == nil {
+		return ""
+	}
+
+	return ctx.ClientIP()
+}
+
+// SetErrorBuilder set error builder of entry which serves the request.
+func SetErrorBuilder(ctx *gin.Context, builder rkerror.ErrorBuilder) {
+	if ctx == nil || builder == nil {
+		return
+	}
+
+	ctx.Set(errorBuilderKey, builder)
+}
+
+// GetErrorBuilder return error builder of entry which serves the request, global one of rkmid if missing.
+func GetErrorBuilder(ctx *gin.Context) rkerror.ErrorBuilder {
+	if ctx != nil {
+		if v, ok := ctx.Get(errorBuilderKey); ok {
+			if builder, ok := v.(rkerror.ErrorBuilder); ok {
+				return builder
+			}
+		}
+	}
+
+	return rkmid.GetErrorBuilder()
+}
+
+// ConvertError rebuild error created by global error builder of rkmid with error builder of entry.
+//
+// Original error would be returned if error builder of entry is missing.
+func ConvertError(ctx *gin.Context, err rkerror.ErrorInterface) rkerror.ErrorInterface {
+	if ctx == nil || err == nil {
+		return err
+	}
+
+	if _, ok := ctx.Get(errorBuilderKey); !ok {
+		return err
+	}
+
+	return GetErrorBuilder(ctx).New(err.Code(), err.Message(), err.Details()...)
+}
+
+// ErrorWithContentType is implemented by errors which should be responded with Content-Type other than
+// application/json, like application/problem+json.
+type ErrorWithContentType interface {
+	ContentType() string
+}
+
+// ErrorWithRequestScope is implemented by errors which carry request scoped values like request id and trace id.
+//
+// A copy of error with request scoped values should be returned, original error may be shared between requests.
+type ErrorWithRequestScope interface {
+	WithRequestScope(instance, requestId, traceId string) rkerror.ErrorInterface
+}
+
+// RenderError write error with error builder of entry.
+//
+// Request scoped values would be filled if error implements ErrorWithRequestScope, and Content-Type would be
+// overridden if error implements ErrorWithContentType.
+func RenderError(ctx *gin.Context, err rkerror.ErrorInterface) {
+	if ctx == nil || err == nil {
+		return
+	}
+
+	err = ConvertError(ctx, err)
+
+	if scoped, ok := err.(ErrorWithRequestScope); ok {
+		instance := ""
+		if ctx.Request != nil && ctx.Request.URL != nil {
+			instance = ctx.Request.URL.Path
+		}
+		err = scoped.WithRequestScope(instance, GetRequestId(ctx), GetTraceId(ctx))
+	}
+
+	if typed, ok := err.(ErrorWithContentType); ok && ctx.Writer != nil {
+		ctx.Writer.Header().Set(rkmid.HeaderContentType, typed.ContentType())
+	}
+
+	ctx.JSON(err.Code(), err)
+}
+
+// AbortWithError abort request and write error with RenderError.
+func AbortWithError(ctx *gin.Context, err rkerror.ErrorInterface) {
+	if ctx == nil {
+		return
+	}
+
+	ctx.Abort()
+	RenderError(ctx, err)
+}
+
+// PeerIdentity identity of client extracted from verified client certificate.
+type PeerIdentity struct {
+	Subject        string   `json:"subject" yaml:"subject"`
+	DNSNames       []string `json:"dnsNames" yaml:"dnsNames"`
+	EmailAddresses []string `json:"emailAddresses" yaml:"emailAddresses"`
+	IPAddresses    []string `json:"ipAddresses" yaml:"ipAddresses"`
+	URIs           []string `json:"uris" yaml:"uris"`
+	SpiffeId       string   `json:"spiffeId" yaml:"spiffeId"`
+}
+
+// GetPeerCertificate return verified client certificate if exists.
+// Certificate only exists while mutual TLS enabled and client certificate was verified by server.
+func GetPeerCertificate(ctx *gin.Context) *x509.Certificate {
+	if ctx == nil || ctx.Request == nil || ctx.Request.TLS == nil {
+		return nil
+	}
+
+	chains := ctx.Request.TLS.VerifiedChains
+	if len(chains) < 1 || len(chains[0]) < 1 {
+		return nil
+	}
+
+	return chains[0][0]
+}
+
+// GetPeerIdentity return identity of client from verified client certificate if exists.
+func GetPeerIdentity(ctx *gin.Context) *PeerIdentity {
+	cert := GetPeerCertificate(ctx)
+	if cert == nil {
+		return nil
+	}
+
+	res := &PeerIdentity{
+		Subject:        cert.Subject.String(),
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		IPAddresses:    make([]string, 0),
+		URIs:           make([]string, 0),
+	}
+
+	for i := range cert.IPAddresses {
+		res.IPAddresses = append(res.IPAddresses, cert.IPAddresses[i].String())
+	}
+
+	for i := range cert.URIs {
+		uri := cert.URIs[i]
+		res.URIs = append(res.URIs, uri.String())
+		if uri.Scheme == "spiffe" && len(res.SpiffeId) < 1 {
+			res.SpiffeId = uri.String()
+		}
+	}
+
+	return res
+}
@@ -6,15 +6,20 @@
 package rkginctx
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	rkcursor "github.com/rookie-ninja/rk-entry/v2/cursor"
+	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-logger"
 	"github.com/rookie-ninja/rk-query"
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -271,6 +276,146 @@ func TestGetCsrfToken(t *testing.T) {
 	assert.Equal(t, "value", GetCsrfToken(ctx))
 }
 
+func TestGetClientIP(t *testing.T) {
+	// with nil
+	assert.Empty(t, GetClientIP(nil))
+
+	ctx, engine := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, GetClientIP(ctx))
+
+	// all proxies are trusted by default
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	ctx.Request.Header.Set("X-Forwarded-For", "1.2.3.4")
+	assert.Equal(t, "1.2.3.4", GetClientIP(ctx))
+
+	// without trusted proxies
+	assert.Nil(t, engine.SetTrustedProxies(nil))
+	assert.Equal(t, "192.0.2.1", GetClientIP(ctx))
+}
+
+func TestErrorBuilder(t *testing.T) {
+	// with nil
+	assert.Equal(t, rkmid.GetErrorBuilder(), GetErrorBuilder(nil))
+	assert.Nil(t, ConvertError(nil, nil))
+	SetErrorBuilder(nil, rkerror.NewErrorBuilderAMZN())
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	err := rkmid.GetErrorBuilder().New(http.StatusBadRequest, "ut-message", "ut-detail")
+
+	// without error builder of entry
+	assert.Equal(t, rkmid.GetErrorBuilder(), GetErrorBuilder(ctx))
+	assert.Equal(t, err, ConvertError(ctx, err))
+
+	// with error builder of entry
+	SetErrorBuilder(ctx, rkerror.NewErrorBuilderAMZN())
+	assert.IsType(t, &rkerror.ErrorBuilderAMZN{}, GetErrorBuilder(ctx))
+	converted := ConvertError(ctx, err)
+	assert.IsType(t, &rkerror.ErrorAMZN{}, converted)
+	assert.Equal(t, http.StatusBadRequest, converted.Code())
+	assert.Equal(t, "ut-message", converted.Message())
+	assert.Equal(t, []interface{}{"ut-detail"}, converted.Details())
+}
+
+func TestRenderError(t *testing.T) {
+	// with nil
+	RenderError(nil, nil)
+	AbortWithError(nil, nil)
+
+	// with default error builder
+	writer := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(writer)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	AbortWithError(ctx, rkmid.GetErrorBuilder().New(http.StatusForbidden, "ut-message"))
+	assert.True(t, ctx.IsAborted())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+	assert.Contains(t, writer.Header().Get(rkmid.HeaderContentType), "application/json")
+	assert.Contains(t, writer.Body.String(), "ut-message")
+
+	// with error implements ErrorWithContentType and ErrorWithRequestScope
+	writer = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(writer)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	ctx.Set(rkmid.HeaderRequestId, "ut-request-id")
+	SetErrorBuilder(ctx, &scopedErrorBuilder{})
+	RenderError(ctx, rkmid.GetErrorBuilder().New(http.StatusForbidden, "ut-message"))
+	assert.False(t, ctx.IsAborted())
+	assert.Equal(t, http.StatusForbidden, writer.Code)
+	assert.Equal(t, "application/ut+json", writer.Header().Get(rkmid.HeaderContentType))
+	assert.Equal(t, `{"code":403,"instance":"/ut-path","requestId":"ut-request-id"}`, writer.Body.String())
+}
+
+type scopedErrorBuilder struct{}
+
+func (b *scopedErrorBuilder) New(code int, msg string, details ...interface{}) rkerror.ErrorInterface {
+	return &scopedError{Status: code}
+}
+
+func (b *scopedErrorBuilder) NewCustom() rkerror.ErrorInterface {
+	return b.New(http.StatusInternalServerError, "")
+}
+
+type scopedError struct {
+	Status    int    `json:"code"`
+	Instance  string `json:"instance"`
+	RequestId string `json:"requestId"`
+}
+
+func (e *scopedError) Error() string          { return "" }
+func (e *scopedError) Code() int              { return e.Status }
+func (e *scopedError) Message() string        { return "" }
+func (e *scopedError) Details() []interface{} { return nil }
+func (e *scopedError) ContentType() string    { return "application/ut+json" }
+func (e *scopedError) WithRequestScope(instance, requestId, traceId string) rkerror.ErrorInterface {
+	return &scopedError{Status: e.Status, Instance: instance, RequestId: requestId}
+}
+
+func TestGetPeerCertificate(t *testing.T) {
+	// with nil context
+	assert.Nil(t, GetPeerCertificate(nil))
+
+	// without tls
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	assert.Nil(t, GetPeerCertificate(ctx))
+
+	// with unverified certificate
+	cert := &x509.Certificate{}
+	ctx.Request.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+	}
+	assert.Nil(t, GetPeerCertificate(ctx))
+
+	// happy case
+	ctx.Request.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	assert.Equal(t, cert, GetPeerCertificate(ctx))
+}
+
+func TestGetPeerIdentity(t *testing.T) {
+	// without tls
+	assert.Nil(t, GetPeerIdentity(nil))
+
+	// happy case
+	spiffeId, _ := url.Parse("spiffe://example.org/ns/default/sa/ut")
+	cert := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ut-client"},
+		DNSNames:    []string{"ut.example.org"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		URIs:        []*url.URL{spiffeId},
+	}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	ctx.Request.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{cert}},
+	}
+
+	peer := GetPeerIdentity(ctx)
+	assert.NotNil(t, peer)
+	assert.Equal(t, "CN=ut-client", peer.Subject)
+	assert.Equal(t, []string{"ut.example.org"}, peer.DNSNames)
+	assert.Equal(t, []string{"127.0.0.1"}, peer.IPAddresses)
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/ut", peer.SpiffeId)
+}
+
 func TestSetPointerCreator(t *testing.T) {
 	assert.Nil(t, pointerCreator)
 
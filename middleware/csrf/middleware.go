@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/csrf"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 	"net/http"
 )
 
@@ -27,7 +28,7 @@ func Middleware(opts ...rkmidcsrf.Option) gin.HandlerFunc {
 		set.Before(beforeCtx)
 
 		if beforeCtx.Output.ErrResp != nil {
-			ctx.JSON(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkginctx.AbortWithError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -47,7 +48,7 @@ func Middleware(opts ...Option) gin.HandlerFunc {
 					return
 				}
 
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to read request body", err))
+				rkginctx.AbortWithError(ctx, rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to read request body", err))
 
 				return
 			}
@@ -55,7 +56,7 @@ func Middleware(opts ...Option) gin.HandlerFunc {
 			// create a buffer and copy decompressed data into it via gzipReader
 			var buf bytes.Buffer
 			if _, err := io.Copy(&buf, gzipReader); err != nil {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to copy request body", err))
+				rkginctx.AbortWithError(ctx, rkmid.GetErrorBuilder().New(http.StatusInternalServerError, "Failed to copy request body", err))
 				return
 			}
 
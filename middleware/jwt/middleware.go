@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/jwt"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 )
 
 // Middleware Add jwt interceptors.
@@ -27,8 +28,7 @@ func Middleware(opts ...rkmidjwt.Option) gin.HandlerFunc {
 
 		// case 1: error response
 		if beforeCtx.Output.ErrResp != nil {
-			ctx.AbortWithStatusJSON(beforeCtx.Output.ErrResp.Code(),
-				beforeCtx.Output.ErrResp)
+			rkginctx.AbortWithError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
@@ -11,6 +11,8 @@ import (
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/log"
 	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
+	"go.uber.org/zap"
+	"net"
 	"strconv"
 )
 
@@ -23,8 +25,19 @@ func Middleware(opts ...rkmidlog.Option) gin.HandlerFunc {
 
 		// call before
 		beforeCtx := set.BeforeCtx(ctx.Request)
+		// use client IP resolved with trusted proxies instead of raw X-Forwarded-For
+		if clientIp := rkginctx.GetClientIP(ctx); len(clientIp) > 0 {
+			beforeCtx.Input.RemoteAddr = remoteAddr(clientIp, ctx.Request.RemoteAddr)
+		}
 		set.Before(beforeCtx)
 
+		// add identity of client if mutual TLS enabled
+		if peer := rkginctx.GetPeerIdentity(ctx); peer != nil {
+			beforeCtx.Output.Event.AddPayloads(
+				zap.String("peerSubject", peer.Subject),
+				zap.String("peerSpiffeId", peer.SpiffeId))
+		}
+
 		ctx.Set(rkmid.EventKey.String(), beforeCtx.Output.Event)
 		ctx.Set(rkmid.LoggerKey.String(), beforeCtx.Output.Logger)
 
@@ -39,3 +52,17 @@ func Middleware(opts ...rkmidlog.Option) gin.HandlerFunc {
 		set.After(beforeCtx, afterCtx)
 	}
 }
+
+// remoteAddr returns remote address with client IP and port of connection, in the same format of rkmid.GetRemoteAddressSet.
+func remoteAddr(clientIp, connAddr string) string {
+	if clientIp == "::1" {
+		clientIp = "localhost"
+	}
+
+	_, port, err := net.SplitHostPort(connAddr)
+	if err != nil {
+		port = "0"
+	}
+
+	return clientIp + ":" + port
+}
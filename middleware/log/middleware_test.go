@@ -48,6 +48,15 @@ func TestInterceptor(t *testing.T) {
 	assert.Equal(t, logger, loggerFromCtx.(*zap.Logger))
 
 	assert.Equal(t, http.StatusOK, ctx.Writer.Status())
+
+	// client IP resolved by gin is used
+	assert.Equal(t, "192.0.2.1:1234", beforeCtx.Input.RemoteAddr)
+}
+
+func TestRemoteAddr(t *testing.T) {
+	assert.Equal(t, "1.2.3.4:1234", remoteAddr("1.2.3.4", "192.0.2.1:1234"))
+	assert.Equal(t, "localhost:1234", remoteAddr("::1", "[::1]:1234"))
+	assert.Equal(t, "1.2.3.4:0", remoteAddr("1.2.3.4", "invalid"))
 }
 
 func assertNotPanic(t *testing.T) {
@@ -12,7 +12,6 @@ import (
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/panic"
 	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
-	"net/http"
 )
 
 // Middleware returns a gin.HandlerFunc (middleware)
@@ -24,7 +23,7 @@ func Middleware(opts ...rkmidpanic.Option) gin.HandlerFunc {
 
 		handlerFunc := func(resp rkerror.ErrorInterface) {
 			if ctx.Writer.Size() < 1 {
-				ctx.AbortWithStatusJSON(http.StatusInternalServerError, resp)
+				rkginctx.AbortWithError(ctx, resp)
 			}
 		}
 		beforeCtx := set.BeforeCtx(rkginctx.GetEvent(ctx), rkginctx.GetLogger(ctx), handlerFunc)
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/ratelimit"
+	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
 )
 
 // Middleware Add rate limit interceptors.
@@ -23,7 +24,7 @@ func Middleware(opts ...rkmidlimit.Option) gin.HandlerFunc {
 		set.Before(beforeCtx)
 
 		if beforeCtx.Output.ErrResp != nil {
-			ctx.AbortWithStatusJSON(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkginctx.AbortWithError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
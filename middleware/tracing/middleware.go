@@ -11,6 +11,7 @@ import (
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/tracing"
 	"github.com/rookie-ninja/rk-gin/v2/middleware/context"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Middleware create a interceptor with opentelemetry.
@@ -24,6 +25,9 @@ func Middleware(opts ...rkmidtrace.Option) gin.HandlerFunc {
 		ctx.Set(rkmid.PropagatorKey.String(), set.GetPropagator())
 
 		beforeCtx := set.BeforeCtx(ctx.Request, false)
+		// override http.client_ip parsed from raw X-Forwarded-For with the one resolved with trusted proxies
+		beforeCtx.Input.Attributes = append(beforeCtx.Input.Attributes,
+			attribute.String("http.client_ip", rkginctx.GetClientIP(ctx)))
 		set.Before(beforeCtx)
 
 		// create request with new context